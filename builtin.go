@@ -0,0 +1,38 @@
+package summer
+
+import (
+	"github.com/linuzilla/summer/gobean"
+	"reflect"
+)
+
+var eventPublisherType = typeOf[EventPublisher]()
+var healthRegistryType = typeOf[HealthRegistry]()
+
+// builtinOf returns what a field matched by type (or Get) takes if it is one of the built-ins of ctx:
+// its event bus for an EventPublisher, its health registry for a *HealthRegistry, nil otherwise. Built-ins are not beans, so they are neither listed
+// by Each, ForEach or Graph, nor do they make a lookup of an interface they happen to implement ambiguous.
+func (ctx *contextManagerImpl) builtinOf(modelType reflect.Type) interface{} {
+	switch modelType {
+	case eventPublisherType:
+		return ctx.events
+	case healthRegistryType:
+		return ctx.health
+	default:
+		return nil
+	}
+}
+
+// isBuiltinField tells whether a field takes a built-in, only a single field matched by type does.
+func (ctx *contextManagerImpl) isBuiltinField(elemField *gobean.ElementField) bool {
+	return elemField.Tag.ByType && !elemField.IsCollection() && ctx.builtinOf(fieldModelType(elemField)) != nil
+}
+
+// injectBuiltin sets a field taking a built-in, it is wired at once since built-ins exist before autowiring.
+func (ctx *contextManagerImpl) injectBuiltin(item *gobean.PopulateItem, elemField *gobean.ElementField) error {
+	if item.Scope == "" {
+		if err := ctx.setValueToField(item, elemField, ctx.builtinOf(elemField.ModelType())); err != nil {
+			return err
+		}
+	}
+	return ctx.markFieldWired(item, elemField, nil, "built-in")
+}
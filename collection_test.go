@@ -0,0 +1,106 @@
+package summer
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+)
+
+type collectionPlugin interface {
+	Label() string
+}
+
+type collectionNamed struct {
+	label string
+	order int
+}
+
+func (n *collectionNamed) Label() string {
+	return n.label
+}
+
+func (n *collectionNamed) SummerOrder() int {
+	return n.order
+}
+
+type collectionPlain struct {
+	label string
+}
+
+func (p *collectionPlain) Label() string {
+	return p.label
+}
+
+type collectionHost struct {
+	Plugins []collectionPlugin          `inject:"*"`
+	ByName  map[string]collectionPlugin `inject:"*"`
+}
+
+type collectionNothing interface {
+	Nothing()
+}
+
+type collectionEmpty struct {
+	Nothings []collectionNothing          `inject:"*"`
+	ByName   map[string]collectionNothing `inject:"*"`
+}
+
+func labels(plugins []collectionPlugin) []string {
+	var result []string
+
+	for _, plugin := range plugins {
+		result = append(result, plugin.Label())
+	}
+	return result
+}
+
+func TestCollectionInjection(t *testing.T) {
+	ctx := New()
+	host := &collectionHost{}
+
+	ctx.Add(host, &collectionPlain{label: "plain"})
+	ctx.AddWithName("first", &collectionNamed{label: "first"})
+	ctx.Add(&collectionNamed{label: "early", order: -1})
+	ctx.AddWithName("tie", &collectionNamed{label: "tie"})
+	ctx.Provide(func() *collectionNamed { return &collectionNamed{label: "provided", order: -2} })
+	ctx.ProvideWithName("lazy", func() *collectionNamed { return &collectionNamed{label: "lazy", order: -3} }, Lazy())
+	ctx.Add(&collectionNamed{label: "last", order: 1})
+
+	if err := ctx.PerformAutoWiringContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// provided beans are sorted once created, ties keep registration order
+	if got, want := labels(host.Plugins), []string{"lazy", "provided", "early", "plain", "first", "tie", "last"}; !slices.Equal(got, want) {
+		t.Errorf("slice: got %v, want %v", got, want)
+	}
+
+	byName := map[string]string{}
+
+	for name, plugin := range host.ByName {
+		byName[name] = plugin.Label()
+	}
+
+	if want := map[string]string{"first": "first", "tie": "tie", "lazy": "lazy"}; !maps.Equal(byName, want) {
+		t.Errorf("map: got %v, want %v", byName, want)
+	}
+}
+
+func TestEmptyCollectionInjection(t *testing.T) {
+	ctx := New()
+	empty := &collectionEmpty{}
+	ctx.Add(empty)
+
+	if err := ctx.PerformAutoWiringContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if empty.Nothings == nil || len(empty.Nothings) != 0 {
+		t.Errorf("slice: got %#v, want an empty slice", empty.Nothings)
+	}
+
+	if empty.ByName == nil || len(empty.ByName) != 0 {
+		t.Errorf("map: got %#v, want an empty map", empty.ByName)
+	}
+}
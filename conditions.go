@@ -0,0 +1,215 @@
+package summer
+
+import (
+	"errors"
+	"fmt"
+	"github.com/linuzilla/summer/gobean"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// property listing active profiles, used if SetActiveProfiles is never called
+const ActiveProfilesProperty = `summer.profiles.active`
+
+// SkippedBean tells which bean was left out of the context because of its conditions, and why.
+type SkippedBean struct {
+	Name   string
+	Source string
+	Reason string
+}
+
+type condition struct {
+	onBeans bool // depends on other beans, evaluated once the others are decided
+	matches func(ctx *contextManagerImpl, self *gobean.PopulateItem) (bool, string)
+}
+
+func withCondition(onBeans bool, matches func(ctx *contextManagerImpl, self *gobean.PopulateItem) (bool, string)) Option {
+	return func(reg *registration) {
+		reg.conditions = append(reg.conditions, condition{onBeans: onBeans, matches: matches})
+	}
+}
+
+// Profile registers the bean only if one of the profiles is active, "!test" means "test" is not active.
+func Profile(profiles ...string) Option {
+	return withCondition(false, func(ctx *contextManagerImpl, _ *gobean.PopulateItem) (bool, string) {
+		active := ctx.ActiveProfiles()
+
+		for _, profile := range profiles {
+			if negated := strings.TrimPrefix(profile, "!"); negated != profile {
+				if !contains(active, negated) {
+					return true, ""
+				}
+			} else if contains(active, profile) {
+				return true, ""
+			}
+		}
+		return false, fmt.Sprintf("profile %v not matched, active profiles: %v", profiles, active)
+	})
+}
+
+// OnProperty registers the bean only if the property equals value,
+// an empty value means the property exists and is not "false".
+func OnProperty(key string, value string) Option {
+	return withCondition(false, func(ctx *contextManagerImpl, _ *gobean.PopulateItem) (bool, string) {
+		if raw, found := ctx.environment.Lookup(key); !found {
+			return false, fmt.Sprintf("property '%s' not found", key)
+		} else if actual, err := ctx.environment.Resolve(raw); err != nil {
+			return false, fmt.Sprintf("property '%s': %v", key, err)
+		} else if (value == "" && actual != "false") || actual == value {
+			return true, ""
+		} else {
+			return false, fmt.Sprintf("property '%s' is '%s', not '%s'", key, actual, value)
+		}
+	})
+}
+
+// OnEnv registers the bean only if the OS environment variable is set.
+func OnEnv(name string) Option {
+	return withCondition(false, func(_ *contextManagerImpl, _ *gobean.PopulateItem) (bool, string) {
+		if _, found := os.LookupEnv(name); found {
+			return true, ""
+		}
+		return false, fmt.Sprintf("environment variable '%s' not set", name)
+	})
+}
+
+// OnMissingBean registers the bean only if no other bean matches T, handy for defaults.
+// Among beans with such condition, the one registered first wins.
+func OnMissingBean[T any]() Option {
+	modelType := modelTypeOf(typeOf[T]())
+
+	return withCondition(true, func(ctx *contextManagerImpl, self *gobean.PopulateItem) (bool, string) {
+		for e := ctx.items.Front(); e != nil; e = e.Next() {
+			item := e.Value.(*gobean.PopulateItem)
+
+			if _, undecided := ctx.conditions[item]; item != self && !undecided && ctx.assignable(item, modelType) {
+				return false, fmt.Sprintf("bean [%s] already matches [%s]", item.BeanType, modelType)
+			}
+		}
+
+		if parentItems := ctx.parentItemsOf(modelType); len(parentItems) > 0 {
+			return false, fmt.Sprintf("bean [%s] of a parent context already matches [%s]", parentItems[0].BeanType, modelType)
+		}
+		return true, ""
+	})
+}
+
+// When registers the bean only if predicate returns true, description is used to report why it is skipped.
+// The predicate runs before any bean of ctx is wired, lookups only find beans of a parent context.
+func When(description string, predicate func(ctx ApplicationContextManager) bool) Option {
+	return withCondition(false, func(ctx *contextManagerImpl, _ *gobean.PopulateItem) (bool, string) {
+		matched := false
+
+		// the predicate may look up beans, it must not run with the lock held
+		ctx.unlocked(func() error {
+			matched = predicate(ctx)
+			return nil
+		})
+
+		if matched {
+			return true, ""
+		}
+		return false, description
+	})
+}
+
+func contains(list []string, s string) bool {
+	for _, element := range list {
+		if element == s {
+			return true
+		}
+	}
+	return false
+}
+
+// applyConditions removes beans whose conditions are not met, conditions depending on other beans go last.
+func (ctx *contextManagerImpl) applyConditions() error {
+	for _, onBeans := range []bool{false, true} {
+		for e := ctx.items.Front(); e != nil; {
+			item, next := e.Value.(*gobean.PopulateItem), e.Next()
+
+			if conditions, pending := ctx.conditions[item]; pending {
+				decided := true
+
+				for _, cond := range conditions {
+					if cond.onBeans != onBeans {
+						decided = false
+					} else if matched, reason := cond.matches(ctx, item); !matched {
+						ctx.skip(item, reason)
+						ctx.items.Remove(e)
+						decided = true
+						break
+					}
+				}
+
+				if decided || onBeans {
+					delete(ctx.conditions, item)
+				}
+			}
+			e = next
+		}
+	}
+
+	// rebuild names, only one of the conditional beans sharing a name should remain
+	itemsMap := map[string]*gobean.PopulateItem{}
+
+	var errs []error
+
+	for e := ctx.items.Front(); e != nil; e = e.Next() {
+		item := e.Value.(*gobean.PopulateItem)
+
+		if item.Name == "" {
+		} else if existing, found := itemsMap[item.Name]; found {
+			errs = append(errs, &BeanError{Name: item.Name, Type: item.BeanType.String(), Source: item.Source, Err: fmt.Errorf("%w, already registered at %s", ErrDuplicateName, existing.Source)})
+		} else {
+			itemsMap[item.Name] = item
+		}
+	}
+	ctx.itemsMap = itemsMap
+	ctx.index = newTypeIndex(ctx.items)
+	return errors.Join(errs...)
+}
+
+func (ctx *contextManagerImpl) skip(item *gobean.PopulateItem, reason string) {
+	ctx.logger.Debug("skipped", beanAttrs(item, slog.String("reason", reason))...)
+	ctx.skipped = append(ctx.skipped, SkippedBean{Name: item.Name, Source: item.Source, Reason: reason})
+}
+
+// skippedHint explains why a bean name is missing, if it was skipped
+func (ctx *contextManagerImpl) skippedHint(beanName string) string {
+	for _, skipped := range ctx.skipped {
+		if skipped.Name == beanName {
+			return fmt.Sprintf(" (skipped: %s)", skipped.Reason)
+		}
+	}
+	return ""
+}
+
+func (ctx *contextManagerImpl) SetActiveProfiles(profiles ...string) {
+	ctx.configure("active profiles", func() {
+		ctx.activeProfiles = profiles
+	})
+}
+
+func (ctx *contextManagerImpl) ActiveProfiles() []string {
+	if ctx.activeProfiles != nil {
+		return ctx.activeProfiles
+	}
+
+	var profiles []string
+
+	if raw, found := ctx.environment.Lookup(ActiveProfilesProperty); found {
+		for _, profile := range strings.Split(raw, ",") {
+			if profile = strings.TrimSpace(profile); profile != "" {
+				profiles = append(profiles, profile)
+			}
+		}
+	}
+	return profiles
+}
+
+func (ctx *contextManagerImpl) SkippedBeans() []SkippedBean {
+	defer ctx.readLock()()
+	return append([]SkippedBean(nil), ctx.skipped...)
+}
@@ -0,0 +1,68 @@
+package summer
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type kitty struct{}
+
+type whenBean struct{}
+
+// whenKittyRegistered adds a whenBean to ctx, kept only if the predicate finds "kitty"
+func whenKittyRegistered(t *testing.T, ctx ApplicationContextManager) {
+	ctx.AddWithOptions(&whenBean{}, When("kitty is registered", func(c ApplicationContextManager) bool {
+		_, err := c.GetByName("kitty")
+		return err == nil
+	}))
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- ctx.PerformAutoWiringContext(context.Background())
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("autowiring deadlocked evaluating a When predicate")
+	}
+}
+
+func TestWhenPredicateDoesNotSeeBeansBeingWired(t *testing.T) {
+	ctx := New()
+	ctx.AddWithName("kitty", &kitty{})
+	whenKittyRegistered(t, ctx)
+
+	if _, err := Get[*whenBean](ctx); err == nil {
+		t.Error("kitty is wired after the predicate, whenBean should be skipped")
+	}
+
+	if skipped := ctx.SkippedBeans(); len(skipped) != 1 || skipped[0].Reason != "kitty is registered" {
+		t.Errorf("skipped beans: %+v", skipped)
+	}
+}
+
+func TestWhenPredicateSeesBeansOfParent(t *testing.T) {
+	parent := New()
+	parent.AddWithName("kitty", &kitty{})
+
+	if err := parent.PerformAutoWiringContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := parent.NewChild()
+	whenKittyRegistered(t, ctx)
+
+	if _, err := Get[*whenBean](ctx); err != nil {
+		t.Errorf("kitty is wired in the parent, whenBean should be kept: %v", err)
+	}
+
+	if skipped := ctx.SkippedBeans(); len(skipped) != 0 {
+		t.Errorf("skipped beans: %+v", skipped)
+	}
+}
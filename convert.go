@@ -0,0 +1,151 @@
+package summer
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// bindValue resolves the tag value of a `value` field and converts it into the field.
+// A structure (other than encoding.TextUnmarshaler) takes a single "${prefix}" and binds its fields
+// from "prefix.fieldName" properties, fields without such property keep their values.
+func bindValue(env *Environment, target reflect.Value, tagValue string) error {
+	if isBindableStruct(target.Type()) {
+		prefix, found := strings.CutPrefix(tagValue, "${")
+		prefix, closed := strings.CutSuffix(prefix, "}")
+
+		if !found || !closed || prefix == "" || strings.ContainsAny(prefix, "${}:") {
+			return fmt.Errorf("structure expects a single '${prefix}', got '%s'", tagValue)
+		}
+		return bindStruct(env, target, prefix)
+	}
+
+	if text, err := env.Resolve(tagValue); err != nil {
+		return err
+	} else {
+		return convertText(target, text)
+	}
+}
+
+func isBindableStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && !reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+func bindStruct(env *Environment, target reflect.Value, prefix string) error {
+	targetType := target.Type()
+
+	for i := 0; i < target.NumField(); i++ {
+		structField := targetType.Field(i)
+
+		if !structField.IsExported() {
+			continue
+		}
+
+		key := prefix + "." + lowerFirst(structField.Name)
+
+		if isBindableStruct(structField.Type) {
+			if err := bindStruct(env, target.Field(i), key); err != nil {
+				return err
+			}
+		} else if raw, found := env.Lookup(key); !found {
+		} else if text, err := env.Resolve(raw); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		} else if err := convertText(target.Field(i), text); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+func lowerFirst(name string) string {
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToLower(r)) + name[size:]
+}
+
+// convertText converts text into string, bool, numbers, time.Duration, encoding.TextUnmarshaler,
+// pointers to them, or slices of them (comma separated).
+func convertText(target reflect.Value, text string) error {
+	if target.CanAddr() && target.Addr().Type().Implements(textUnmarshalerType) {
+		return target.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text))
+	}
+
+	if target.Type() == durationType {
+		if duration, err := time.ParseDuration(text); err != nil {
+			return err
+		} else {
+			target.SetInt(int64(duration))
+			return nil
+		}
+	}
+
+	switch target.Kind() {
+	case reflect.String:
+		target.SetString(text)
+
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(text); err != nil {
+			return err
+		} else {
+			target.SetBool(b)
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if i, err := strconv.ParseInt(text, 0, target.Type().Bits()); err != nil {
+			return err
+		} else {
+			target.SetInt(i)
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if u, err := strconv.ParseUint(text, 0, target.Type().Bits()); err != nil {
+			return err
+		} else {
+			target.SetUint(u)
+		}
+
+	case reflect.Float32, reflect.Float64:
+		if f, err := strconv.ParseFloat(text, target.Type().Bits()); err != nil {
+			return err
+		} else {
+			target.SetFloat(f)
+		}
+
+	case reflect.Ptr:
+		value := reflect.New(target.Type().Elem())
+
+		if err := convertText(value.Elem(), text); err != nil {
+			return err
+		}
+		target.Set(value)
+
+	case reflect.Slice:
+		var items []string
+
+		if strings.TrimSpace(text) != "" {
+			items = strings.Split(text, ",")
+		}
+
+		slice := reflect.MakeSlice(target.Type(), len(items), len(items))
+
+		for i, item := range items {
+			if err := convertText(slice.Index(i), strings.TrimSpace(item)); err != nil {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+		}
+		target.Set(slice)
+
+	default:
+		return fmt.Errorf("unable to convert into [%s]", target.Type())
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package summer
+
+import (
+	"context"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConvertText(t *testing.T) {
+	port := 8080
+
+	for _, test := range []struct {
+		text string
+		want interface{}
+	}{
+		{"summer", "summer"},
+		{"true", true},
+		{"-42", -42},
+		{"0x10", int64(16)},
+		{"255", uint8(255)},
+		{"0.25", 0.25},
+		{"1m30s", 90 * time.Second},
+		{"8080", &port},
+		{"10.0.0.1", netip.MustParseAddr("10.0.0.1")},
+		{"a, b ,c", []string{"a", "b", "c"}},
+		{"1s,2ms", []time.Duration{time.Second, 2 * time.Millisecond}},
+		{" ", []int{}},
+	} {
+		target := reflect.New(reflect.TypeOf(test.want)).Elem()
+
+		if err := convertText(target, test.text); err != nil {
+			t.Errorf("%q into %T: %v", test.text, test.want, err)
+		} else if !reflect.DeepEqual(target.Interface(), test.want) {
+			t.Errorf("%q into %T: got %v, want %v", test.text, test.want, target.Interface(), test.want)
+		}
+	}
+}
+
+func TestConvertTextFails(t *testing.T) {
+	for _, test := range []struct {
+		text   string
+		target interface{}
+		want   string
+	}{
+		{"yes please", false, "invalid syntax"},
+		{"256", uint8(0), "value out of range"},
+		{"1.5", 0, "invalid syntax"},
+		{"soon", time.Duration(0), "invalid duration"},
+		{"1,x,3", []int(nil), "item 1: "},
+		{"here", netip.Addr{}, "ParseAddr"},
+		{"x", map[string]string(nil), "unable to convert into [map[string]string]"},
+	} {
+		target := reflect.New(reflect.TypeOf(test.target)).Elem()
+
+		if err := convertText(target, test.text); err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%q into %T: got %v, want %q", test.text, test.target, err, test.want)
+		}
+	}
+}
+
+type convertLimits struct {
+	Timeout time.Duration
+	Retries int
+}
+
+type convertServer struct {
+	Host    string
+	Port    int
+	Peers   []string
+	Limits  convertLimits
+	Listen  netip.Addr
+	comment string
+}
+
+func TestBindStruct(t *testing.T) {
+	env := NewEnvironment(NewMapSource("m", map[string]string{
+		"server.host":           "${host:localhost}",
+		"server.port":           "8080",
+		"server.peers":          "a,b",
+		"server.limits.timeout": "5s",
+		"server.listen":         "127.0.0.1",
+		"server.comment":        "unexported",
+	}))
+
+	server := convertServer{Limits: convertLimits{Retries: 3}}
+
+	if err := bindValue(env, reflect.ValueOf(&server).Elem(), "${server}"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := convertServer{
+		Host:   "localhost",
+		Port:   8080,
+		Peers:  []string{"a", "b"},
+		Limits: convertLimits{Timeout: 5 * time.Second, Retries: 3},
+		Listen: netip.MustParseAddr("127.0.0.1"),
+	}
+
+	if !reflect.DeepEqual(server, want) {
+		t.Errorf("got %+v, want %+v", server, want)
+	}
+
+	for _, tagValue := range []string{"server", "${server:x}", "${a}.${b}", "${}"} {
+		if err := bindValue(env, reflect.ValueOf(&server).Elem(), tagValue); err == nil {
+			t.Errorf("%q: a structure should take a single '${prefix}'", tagValue)
+		}
+	}
+}
+
+func TestBindFailsWithPropertyName(t *testing.T) {
+	for _, test := range []struct {
+		properties map[string]string
+		want       string
+	}{
+		{map[string]string{"server.port": "http"}, "server.port: "},
+		{map[string]string{"server.limits.timeout": "soon"}, "server.limits.timeout: "},
+		{map[string]string{"server.peers": "${missing}"}, "server.peers: "},
+	} {
+		env := NewEnvironment(NewMapSource("m", test.properties))
+		var server convertServer
+
+		if err := bindValue(env, reflect.ValueOf(&server).Elem(), "${server}"); err == nil || !strings.HasPrefix(err.Error(), test.want) {
+			t.Errorf("%v: got %v, want %q", test.properties, err, test.want)
+		}
+	}
+}
+
+type convertBean struct {
+	Port    int           `value:"${server.port}"`
+	Timeout time.Duration `value:"${server.timeout:30s}"`
+	Hosts   []string      `value:"${server.hosts}"`
+	Server  convertServer `value:"${server}"`
+}
+
+func TestValueInjection(t *testing.T) {
+	ctx := New()
+	ctx.SetEnvironment(NewEnvironment(NewMapSource("m", map[string]string{
+		"server.port":  "8080",
+		"server.hosts": "a,b",
+		"server.host":  "h",
+	})))
+
+	bean := &convertBean{}
+	ctx.Add(bean)
+
+	if err := ctx.PerformAutoWiringContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if bean.Port != 8080 || bean.Timeout != 30*time.Second || len(bean.Hosts) != 2 || bean.Server.Host != "h" {
+		t.Errorf("got %+v", bean)
+	}
+}
+
+func TestValueInjectionFailsWithPropertyName(t *testing.T) {
+	ctx := New()
+	ctx.SetEnvironment(NewEnvironment(NewMapSource("m", map[string]string{
+		"server.port":           "8080",
+		"server.hosts":          "a,b",
+		"server.limits.retries": "many",
+	})))
+	ctx.Add(&convertBean{})
+
+	err := ctx.PerformAutoWiringContext(context.Background())
+
+	if err == nil || !strings.Contains(err.Error(), "`value:\"${server}\"` ]: server.limits.retries: ") {
+		t.Errorf("got %v, want the field and the property named", err)
+	}
+}
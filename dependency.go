@@ -0,0 +1,170 @@
+package summer
+
+import (
+	"github.com/linuzilla/summer/gobean"
+)
+
+// dependencyTargets lists the beans a field would be injected with, as far as can be told before autowiring:
+// nothing for an ambiguous or missing bean, every match for a collection.
+func (ctx *contextManagerImpl) dependencyTargets(owner *gobean.PopulateItem, elemField *gobean.ElementField) []*gobean.PopulateItem {
+	if elemField.IsCollection() {
+		matchedItems, _ := ctx.collectionMatches(owner, elemField)
+		return matchedItems
+	}
+
+	if elemField.Tag.ByType {
+		matchedItems := ctx.itemsOf(fieldModelType(elemField))
+
+		if len(matchedItems) == 0 {
+			matchedItems = ctx.parentItemsOf(fieldModelType(elemField))
+		}
+
+		if len(matchedItems) > 0 {
+			if len(matchedItems) > 1 {
+				return nil
+			}
+			return []*gobean.PopulateItem{matchedItems[0]}
+		}
+	} else if item := ctx.itemByName(elemField.Tag.Name); item != nil {
+		return []*gobean.PopulateItem{item}
+	}
+
+	if item := ctx.itemByName(elemField.Tag.Fallback); item != nil && elemField.Tag.Fallback != "" {
+		return []*gobean.PopulateItem{item}
+	}
+	return nil
+}
+
+type dependencyEdge struct {
+	field  *gobean.ElementField
+	target *gobean.PopulateItem
+}
+
+func (ctx *contextManagerImpl) dependencyEdges(item *gobean.PopulateItem) []dependencyEdge {
+	var edges []dependencyEdge
+
+	for _, elemField := range item.Fields {
+		if isProviderField(elemField) { // resolved after autowiring, not a dependency
+			continue
+		}
+
+		for _, target := range ctx.dependencyTargets(item, elemField) {
+			edges = append(edges, dependencyEdge{field: elemField, target: target})
+		}
+	}
+	return edges
+}
+
+// detectCycles finds strongly connected components (Tarjan's algorithm) among beans not wired yet,
+// and returns one cycle path for each of them. Components are found dependencies first, so the beans
+// are also returned in the order they can be wired in a single pass.
+func (ctx *contextManagerImpl) detectCycles() ([]*gobean.PopulateItem, error) {
+	var items []*gobean.PopulateItem
+
+	edges := map[*gobean.PopulateItem][]dependencyEdge{}
+
+	for e := ctx.items.Front(); e != nil; e = e.Next() {
+		if item := e.Value.(*gobean.PopulateItem); !item.Wired {
+			items = append(items, item)
+			edges[item] = ctx.dependencyEdges(item)
+		}
+	}
+
+	index := map[*gobean.PopulateItem]int{}
+	lowLink := map[*gobean.PopulateItem]int{}
+	onStack := map[*gobean.PopulateItem]bool{}
+
+	var stack, order []*gobean.PopulateItem
+	var cycles [][]CycleStep
+	var strongConnect func(item *gobean.PopulateItem)
+
+	strongConnect = func(item *gobean.PopulateItem) {
+		index[item] = len(index)
+		lowLink[item] = index[item]
+		stack = append(stack, item)
+		onStack[item] = true
+
+		for _, edge := range edges[item] {
+			if _, visited := index[edge.target]; !visited {
+				if _, pending := edges[edge.target]; pending {
+					strongConnect(edge.target)
+					lowLink[item] = min(lowLink[item], lowLink[edge.target])
+				}
+			} else if onStack[edge.target] {
+				lowLink[item] = min(lowLink[item], index[edge.target])
+			}
+		}
+
+		if lowLink[item] == index[item] {
+			component := map[*gobean.PopulateItem]bool{}
+
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				component[top] = true
+				order = append(order, top)
+
+				if top == item {
+					break
+				}
+			}
+
+			if path := ctx.cyclePath(item, component, edges); path != nil {
+				cycles = append(cycles, path)
+			}
+		}
+	}
+
+	for _, item := range items {
+		if _, visited := index[item]; !visited {
+			strongConnect(item)
+		}
+	}
+
+	if len(cycles) > 0 {
+		return nil, &CycleError{Cycles: cycles}
+	}
+	return order, nil
+}
+
+// cyclePath finds the shortest way from start back to itself within a component, nil if there is none.
+func (ctx *contextManagerImpl) cyclePath(start *gobean.PopulateItem, component map[*gobean.PopulateItem]bool, edges map[*gobean.PopulateItem][]dependencyEdge) []CycleStep {
+	via := map[*gobean.PopulateItem]dependencyEdge{}
+	from := map[*gobean.PopulateItem]*gobean.PopulateItem{}
+	queue := []*gobean.PopulateItem{start}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for _, edge := range edges[current] {
+			if !component[edge.target] {
+				continue
+			} else if edge.target == start {
+				path := []CycleStep{ctx.cycleStep(current, edge)}
+
+				for node := current; node != start; node = from[node] {
+					path = append([]CycleStep{ctx.cycleStep(from[node], via[node])}, path...)
+				}
+				return path
+			} else if _, seen := from[edge.target]; !seen {
+				from[edge.target] = current
+				via[edge.target] = edge
+				queue = append(queue, edge.target)
+			}
+		}
+	}
+	return nil
+}
+
+func (ctx *contextManagerImpl) cycleStep(item *gobean.PopulateItem, edge dependencyEdge) CycleStep {
+	return CycleStep{
+		BeanType: item.BeanType.String(),
+		Source:   item.Source,
+		Field:    edge.field.StructField.Name,
+		Tag:      edge.field.Tag.Raw,
+		Location: edge.field.FullName(ctx.injectionTag),
+		Target:   edge.target.BeanType.String(),
+	}
+}
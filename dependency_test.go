@@ -0,0 +1,101 @@
+package summer
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type cycleOutside struct {
+	A *cycleA `inject:"*"`
+}
+
+type cycleA struct {
+	B *cycleB `inject:"*"`
+}
+
+type cycleB struct {
+	C *cycleC `inject:"*"`
+}
+
+type cycleC struct {
+	A *cycleA `inject:"*"`
+}
+
+type cycleSelf struct {
+	Self *cycleSelf `inject:"*"`
+}
+
+type cycleMissing struct {
+	Absent *cycleAbsent `inject:"*"`
+}
+
+type cycleAbsent struct{}
+
+type cycleWaiting struct {
+	Missing *cycleMissing `inject:"*"`
+}
+
+func TestCyclePath(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		beans []interface{}
+		want  [][3]string // bean, field, target
+	}{
+		{"A->B->C->A", []interface{}{&cycleOutside{}, &cycleA{}, &cycleB{}, &cycleC{}}, [][3]string{
+			{"*summer.cycleA", "B", "*summer.cycleB"},
+			{"*summer.cycleB", "C", "*summer.cycleC"},
+			{"*summer.cycleC", "A", "*summer.cycleA"},
+		}},
+		{"self reference", []interface{}{&cycleSelf{}}, [][3]string{
+			{"*summer.cycleSelf", "Self", "*summer.cycleSelf"},
+		}},
+	} {
+		ctx := New()
+		ctx.Add(test.beans...)
+
+		var cycleError *CycleError
+
+		if err := ctx.PerformAutoWiringContext(context.Background()); !errors.As(err, &cycleError) {
+			t.Errorf("%s: got %v, want a CycleError", test.name, err)
+			continue
+		} else if len(cycleError.Cycles) != 1 {
+			t.Errorf("%s: got %d cycles, want 1", test.name, len(cycleError.Cycles))
+			continue
+		}
+
+		var path [][3]string
+
+		for _, step := range cycleError.Cycles[0] {
+			path = append(path, [3]string{step.BeanType, step.Field, step.Target})
+		}
+
+		if !reflect.DeepEqual(path, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, path, test.want)
+		}
+	}
+}
+
+func TestMissingDependencyIsNotACycle(t *testing.T) {
+	ctx := New()
+	ctx.Add(&cycleWaiting{}, &cycleMissing{})
+
+	err := ctx.PerformAutoWiringContext(context.Background())
+
+	var unsatisfied *UnsatisfiedDependencyError
+
+	if !errors.As(err, &unsatisfied) {
+		t.Fatalf("got %v, want an UnsatisfiedDependencyError", err)
+	} else if errors.Is(err, ErrCycle) {
+		t.Errorf("got %v, a missing dependency is not %v", err, ErrCycle)
+	}
+
+	if len(unsatisfied.Errs) != 1 || !errors.Is(unsatisfied.Errs[0], ErrNoSuchBean) {
+		t.Errorf("failed fields: got %v, want the missing bean", unsatisfied.Errs)
+	}
+
+	if len(unsatisfied.Fields) != 1 || unsatisfied.Fields[0] != "struct: *summer.cycleWaiting [ Missing *summer.cycleMissing `inject:\"*\"` ]" {
+		t.Errorf("waiting fields: got %q, want the field of cycleWaiting", unsatisfied.Fields)
+	}
+}
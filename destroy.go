@@ -0,0 +1,90 @@
+package summer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/linuzilla/summer/gobean"
+	"io"
+)
+
+// destroyBean calls PreSummerDestroy, or Close if the bean is an io.Closer,
+// giving up (but not interrupting) when goCtx is done.
+func destroyBean(goCtx context.Context, item *gobean.PopulateItem) error {
+	var destroy func() error
+
+	switch bean := item.Bean.(type) {
+	case HavePreDestroy:
+		destroy = bean.PreSummerDestroy
+	case io.Closer:
+		destroy = bean.Close
+	default:
+		return nil
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- destroy()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-goCtx.Done():
+		return goCtx.Err()
+	}
+}
+
+func (ctx *contextManagerImpl) Close(goCtx context.Context) error {
+	if ctx.state.Load() == stateWiring {
+		return ErrWiringInProgress
+	}
+
+	ctx.closeMu.Lock()
+	defer ctx.closeMu.Unlock()
+
+	if ctx.closed {
+		return nil
+	}
+	ctx.closed = true
+
+	var errs []error
+
+	if err := ctx.events.Publish(goCtx, ContextClosing{Context: ctx}); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := ctx.Stop(goCtx); err != nil {
+		errs = append(errs, err)
+	}
+
+	// beans may still be needed by listeners of async events
+	if err := ctx.events.shutdown(goCtx); err != nil {
+		errs = append(errs, err)
+	}
+
+	unlock := ctx.readLock()
+	wiredOrder := ctx.wiredOrder
+	unlock()
+
+	for i := len(wiredOrder) - 1; i >= 0; i-- {
+		item := wiredOrder[i]
+
+		if item.Scope != "" { // instances of scoped beans are not tracked
+			continue
+		} else if item.Lazy && !item.LazyInitialized() { // never used
+			continue
+		} else if err := goCtx.Err(); err != nil {
+			errs = append(errs, fmt.Errorf("close aborted, %d beans left: %w", i+1, err))
+			break
+		}
+
+		ctx.logger.Debug("destroy", beanAttrs(item)...)
+
+		if err := destroyBean(goCtx, item); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", item.Source, err))
+		}
+	}
+	return errors.Join(errs...)
+}
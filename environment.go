@@ -0,0 +1,143 @@
+package summer
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const DefaultValueTag = `value`
+
+// placeholders referring to each other more than this are considered circular
+const maxPlaceholderDepth = 32
+
+// PropertySource provides properties, such as OS environment variables or a JSON file.
+type PropertySource interface {
+	Name() string
+	Lookup(key string) (string, bool)
+	Keys() []string
+}
+
+// Environment resolves properties from layers of property sources, the first source having a property wins.
+//
+//	env := summer.NewEnvironment(summer.ArgsSource(os.Args[1:]), summer.OSEnvSource())
+//	env.AddLast(source) // source loaded by PropertyFile(".env") or PropertyFile("config.json")
+type Environment struct {
+	sources []PropertySource
+	parent  *Environment // looked up after every source, for the environment of a child context
+}
+
+func NewEnvironment(sources ...PropertySource) *Environment {
+	return &Environment{sources: sources}
+}
+
+// AddFirst puts a source in front of the others, it overrides every existing source.
+func (env *Environment) AddFirst(source PropertySource) *Environment {
+	env.sources = append([]PropertySource{source}, env.sources...)
+	return env
+}
+
+// AddLast puts a source after the others, it is only looked up if none of the existing sources has the property.
+func (env *Environment) AddLast(source PropertySource) *Environment {
+	env.sources = append(env.sources, source)
+	return env
+}
+
+// Sources lists the sources in lookup order, those of the parent environment (if any) last.
+func (env *Environment) Sources() []PropertySource {
+	if env.parent == nil {
+		return env.sources
+	}
+	return append(slices.Clip(env.sources), env.parent.Sources()...)
+}
+
+// Lookup returns the raw value of a property, placeholders in it are not resolved.
+func (env *Environment) Lookup(key string) (string, bool) {
+	for _, source := range env.Sources() {
+		if value, found := source.Lookup(key); found {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// Keys lists every property known by the sources, sorted.
+func (env *Environment) Keys() []string {
+	seen := map[string]bool{}
+	var keys []string
+
+	for _, source := range env.Sources() {
+		for _, key := range source.Keys() {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Resolve replaces every "${key}" or "${key:default}" in text, placeholders may be nested.
+func (env *Environment) Resolve(text string) (string, error) {
+	return env.resolve(text, 0)
+}
+
+func (env *Environment) resolve(text string, depth int) (string, error) {
+	if depth > maxPlaceholderDepth {
+		return "", fmt.Errorf("circular placeholder in '%s'", text)
+	}
+
+	var str strings.Builder
+
+	for {
+		start := strings.Index(text, "${")
+
+		if start < 0 {
+			str.WriteString(text)
+			return str.String(), nil
+		}
+
+		end := closingBrace(text, start+2)
+
+		if end < 0 {
+			return "", fmt.Errorf("unterminated placeholder in '%s'", text)
+		}
+
+		str.WriteString(text[:start])
+
+		key, replacement, hasDefault := strings.Cut(text[start+2:end], ":")
+
+		if value, found := env.Lookup(key); found {
+			replacement = value
+		} else if !hasDefault {
+			return "", fmt.Errorf("%w: '%s'", ErrNoSuchProperty, key)
+		}
+
+		if resolved, err := env.resolve(replacement, depth+1); err != nil {
+			return "", err
+		} else {
+			str.WriteString(resolved)
+		}
+		text = text[end+1:]
+	}
+}
+
+// closingBrace finds the "}" matching a "${" ending right before from.
+func closingBrace(text string, from int) int {
+	nested := 0
+
+	for i := from; i < len(text); i++ {
+		switch {
+		case strings.HasPrefix(text[i:], "${"):
+			nested++
+			i++
+		case text[i] == '}' && nested == 0:
+			return i
+		case text[i] == '}':
+			nested--
+		}
+	}
+	return -1
+}
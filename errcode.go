@@ -1,19 +1,40 @@
 package summer
 
-type errCode int
+// ErrCode is the status of the outcome of Run, see RunError.
+type ErrCode int
 
 // ensure "ErrCode" implements "error"
-var _ error = (*errCode)(nil)
+var _ error = (*ErrCode)(nil)
 
 const (
-	StatusOK errCode = 200
+	StatusOK              ErrCode = 200
+	StatusRunnerFailed    ErrCode = 500
+	StatusWiringFailed    ErrCode = 501
+	StatusStartFailed     ErrCode = 502
+	StatusShutdownFailed  ErrCode = 503
+	StatusShutdownTimeout ErrCode = 504
 )
 
-var errCodeLookup = map[errCode]string{
-	StatusOK: "Ok",
+var errCodeLookup = map[ErrCode]string{
+	StatusOK:              "Ok",
+	StatusRunnerFailed:    "Runner failed",
+	StatusWiringFailed:    "Autowiring failed",
+	StatusStartFailed:     "Lifecycle start failed",
+	StatusShutdownFailed:  "Shutdown failed",
+	StatusShutdownTimeout: "Shutdown timed out",
 }
 
-func (e errCode) String() string {
+// process exit code of the outcome of Run
+var exitCodeLookup = map[ErrCode]int{
+	StatusOK:              0,
+	StatusRunnerFailed:    1,
+	StatusWiringFailed:    2,
+	StatusStartFailed:     3,
+	StatusShutdownFailed:  4,
+	StatusShutdownTimeout: 5,
+}
+
+func (e ErrCode) String() string {
 	if val, ok := errCodeLookup[e]; ok {
 		return val
 	} else {
@@ -21,6 +42,15 @@ func (e errCode) String() string {
 	}
 }
 
-func (e errCode) Error() string {
+func (e ErrCode) Error() string {
 	return e.String()
 }
+
+// ExitCode is the process exit code, 0 for StatusOK and 1 for an unknown status.
+func (e ErrCode) ExitCode() int {
+	if val, ok := exitCodeLookup[e]; ok {
+		return val
+	} else {
+		return 1
+	}
+}
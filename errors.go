@@ -0,0 +1,217 @@
+package summer
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+var (
+	// no wired bean matches the requested type or name
+	ErrNoSuchBean = errors.New("no such bean")
+
+	// more than one bean matches the requested type, use a name instead
+	ErrAmbiguousBean = errors.New("multiple match found")
+
+	// the bean was found but it can not be converted to the requested type
+	ErrWrongType = errors.New("bean is not of the required type")
+
+	// the bean was found but some of its dependencies have not been injected yet
+	ErrNotWired = errors.New("bean not fully wired yet")
+
+	// a placeholder refers to a property no source has, and gives no default
+	ErrNoSuchProperty = errors.New("no such property")
+
+	// beans depend on each other, none of them can be wired
+	ErrCycle = errors.New("circular dependency")
+
+	// more than one bean registered with the same name
+	ErrDuplicateName = errors.New("duplicate bean name")
+
+	// the field is neither exported nor having a setter
+	ErrNotSettable = errors.New("no setter or field not settable")
+
+	// the setter does not take a single argument the bean can be assigned to
+	ErrSetterSignature = errors.New("setter signature mismatch")
+
+	// beans can not be added while autowiring is in progress
+	ErrWiringInProgress = errors.New("autowiring in progress")
+
+	// beans can not be added once autowiring is done
+	ErrFrozen = errors.New("application context frozen after autowiring")
+
+	// autowiring made no progress while some fields are still not wired
+	ErrUnsatisfiedDependency = errors.New("unsatisfied dependency")
+
+	// the manifest of a plugin requires a host API version other than APIVersion
+	ErrIncompatiblePlugin = errors.New("incompatible plugin")
+
+	// a plugin requires another plugin which is missing, of another version or failed to load
+	ErrPluginDependency = errors.New("plugin dependency not satisfied")
+)
+
+// LookupError is returned by Get, GetByName and their generic counterparts,
+// use errors.Is against ErrNoSuchBean, ErrAmbiguousBean, ErrWrongType or ErrNotWired to tell them apart.
+type LookupError struct {
+	Type       reflect.Type // the requested type
+	Name       string       // the requested bean name, empty for lookup by type
+	Matched    int          // number of beans matched the requested type
+	Candidates []string     // beans matched, only given for ErrAmbiguousBean while autowiring
+	Err        error
+}
+
+func (e *LookupError) Error() string {
+	switch {
+	case e.Name != "" && e.Type != nil:
+		return fmt.Sprintf("bean '%s' [%s]: %v", e.Name, e.Type, e.Err)
+	case e.Name != "":
+		return fmt.Sprintf("bean '%s': %v", e.Name, e.Err)
+	case len(e.Candidates) > 0:
+		return fmt.Sprintf("[%s]: %v (%d candidates: %s), consider using match by name instead", e.Type, e.Err, e.Matched, strings.Join(e.Candidates, ", "))
+	case e.Matched > 1:
+		return fmt.Sprintf("[%s]: %v (%d candidates)", e.Type, e.Err, e.Matched)
+	default:
+		return fmt.Sprintf("[%s]: %v", e.Type, e.Err)
+	}
+}
+
+func (e *LookupError) Unwrap() error {
+	return e.Err
+}
+
+// BeanError is a problem of a bean as a whole, such as ErrDuplicateName.
+type BeanError struct {
+	Name   string
+	Type   string
+	Source string
+	Err    error
+}
+
+func (e *BeanError) Error() string {
+	if e.Name != "" {
+		return fmt.Sprintf("%s: bean '%s': %v", e.Source, e.Name, e.Err)
+	}
+	return fmt.Sprintf("%s: %v", e.Source, e.Err)
+}
+
+func (e *BeanError) Unwrap() error {
+	return e.Err
+}
+
+// InjectionError is a problem injecting a field, Err is usually a *LookupError,
+// ErrNotSettable or ErrSetterSignature.
+type InjectionError struct {
+	BeanType  string
+	Source    string
+	Field     string
+	FieldType string
+	Tag       string
+	Location  string // the field in ElementField.FullName style
+	Err       error
+}
+
+func (e *InjectionError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Location, e.Err)
+}
+
+func (e *InjectionError) Unwrap() error {
+	return e.Err
+}
+
+// PostConstructError is returned by autowiring when PostSummerConstructE or PostSummerConstructCtx fails.
+type PostConstructError struct {
+	Source     string   // the bean failed to initialize
+	Dependents []string // beans depending on it, directly or not, nearest first
+	Err        error
+}
+
+func (e *PostConstructError) Error() string {
+	var str strings.Builder
+
+	str.WriteString(fmt.Sprintf("%s: post construct failed: %v", e.Source, e.Err))
+
+	for _, dependent := range e.Dependents {
+		str.WriteString("\n  required by ")
+		str.WriteString(dependent)
+	}
+	return str.String()
+}
+
+func (e *PostConstructError) Unwrap() error {
+	return e.Err
+}
+
+// CycleStep is a field of a bean leading to the next bean of a cycle.
+type CycleStep struct {
+	BeanType string
+	Source   string
+	Field    string
+	Tag      string
+	Location string // the field in ElementField.FullName style
+	Target   string // type of the bean the field depends on
+}
+
+// CycleError is returned by autowiring when beans depend on each other, one path for each cycle.
+type CycleError struct {
+	Cycles [][]CycleStep
+}
+
+func (e *CycleError) Error() string {
+	var str strings.Builder
+
+	str.WriteString(ErrCycle.Error())
+
+	for i, cycle := range e.Cycles {
+		str.WriteString(fmt.Sprintf("\n cycle #%d:", i+1))
+
+		for _, step := range cycle {
+			str.WriteString(fmt.Sprintf("\n  %s -> %s", step.Location, step.Target))
+		}
+	}
+	return str.String()
+}
+
+func (e *CycleError) Unwrap() error {
+	return ErrCycle
+}
+
+// UnsatisfiedDependencyError is returned by autowiring when some fields can not be wired, for a reason other than a cycle.
+type UnsatisfiedDependencyError struct {
+	Errs   []error  // fields failed to be injected, a missing or an ambiguous bean for instance
+	Fields []string // fields waiting for a bean never wired, in ElementField.FullName style
+}
+
+func (e *UnsatisfiedDependencyError) Error() string {
+	var str strings.Builder
+
+	str.WriteString(fmt.Sprintf("autowiring failed, %v:", ErrUnsatisfiedDependency))
+
+	for _, err := range e.Errs {
+		str.WriteString("\n  " + err.Error())
+	}
+
+	for _, field := range e.Fields {
+		str.WriteString("\n  " + field)
+	}
+	return str.String()
+}
+
+func (e *UnsatisfiedDependencyError) Unwrap() []error {
+	return append([]error{ErrUnsatisfiedDependency}, e.Errs...)
+}
+
+// PluginError is given to the callback of LoadPlugins for a plugin skipped by its manifest.
+type PluginError struct {
+	File string
+	Name string // the plugin name of the manifest
+	Err  error
+}
+
+func (e *PluginError) Error() string {
+	return fmt.Sprintf("%s: plugin '%s': %v", e.File, e.Name, e.Err)
+}
+
+func (e *PluginError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,39 @@
+package summer
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+var errBoom = errors.New("boom")
+
+type errorsDatabase struct{}
+
+type errorsUnsettable struct {
+	port int `value:"${port:80}"`
+}
+
+func TestProviderErrorIsKept(t *testing.T) {
+	ctx := New()
+	ctx.Provide(func() (*errorsDatabase, error) { return nil, errBoom })
+
+	err := ctx.PerformAutoWiringContext(context.Background())
+
+	var beanErr *BeanError
+
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("the error of the provider is lost: %v", err)
+	} else if !errors.As(err, &beanErr) || beanErr.Type != "*summer.errorsDatabase" {
+		t.Fatalf("expected a *BeanError, got %v", err)
+	}
+}
+
+func TestUnsettableValueField(t *testing.T) {
+	ctx := New()
+	ctx.Add(&errorsUnsettable{})
+
+	if err := ctx.PerformAutoWiringContext(context.Background()); !errors.Is(err, ErrNotSettable) {
+		t.Fatalf("expected %v, got %v", ErrNotSettable, err)
+	}
+}
@@ -0,0 +1,296 @@
+package summer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/linuzilla/summer/gobean"
+	"log/slog"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+const (
+	DefaultEventWorkers   = 4
+	DefaultEventQueueSize = 64
+)
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// ErrEventBusClosed is returned by PublishAsync once the context is closed
+var ErrEventBusClosed = errors.New("event bus closed")
+
+// EventPublisher is built into every context, inject it with `inject:"*"`. It is not a bean:
+// Each, ForEach and Graph do not list it.
+// Events published in a child context are delivered to listeners of its ancestors as well.
+type EventPublisher interface {
+	// Publish delivers the event to every listener, in order, before returning.
+	// Every listener is called even if some of them fail, their errors are joined together.
+	Publish(goCtx context.Context, event interface{}) error
+
+	// PublishAsync queues the event for the workers, it only blocks while the queue is full.
+	// Errors of listeners are given to the handler set by SetEventErrorHandler.
+	PublishAsync(goCtx context.Context, event interface{}) error
+}
+
+// EventListener is implemented by a bean interested in events of type E, it is subscribed once wired.
+// Listeners are called in the order given by SummerOrder (see HaveOrder), then in wiring order.
+// E can be an interface, EventListener[any] receives every event.
+type EventListener[E any] interface {
+	OnSummerEvent(goCtx context.Context, event E) error
+}
+
+// ContextWired is published once autowiring succeeds.
+type ContextWired struct {
+	Context ApplicationContextManager
+}
+
+// ContextClosing is published when Close is called, before any bean is destroyed.
+type ContextClosing struct {
+	Context ApplicationContextManager
+}
+
+// PluginLoaded is published by LoadPlugins for every plugin added, beans of the context are not wired yet
+// so only listeners added by Subscribe, or those of a parent context, receive it.
+type PluginLoaded struct {
+	Context  ApplicationContextManager
+	BeanName string
+	File     string
+	Module   interface{}
+}
+
+type eventListener struct {
+	eventType reflect.Type
+	order     int
+	source    string
+	call      func(goCtx context.Context, event reflect.Value) error
+}
+
+type asyncEvent struct {
+	goCtx context.Context
+	event interface{}
+}
+
+type eventBus struct {
+	ctx       *contextManagerImpl
+	mu        sync.RWMutex
+	listeners []*eventListener // sorted by order, stable
+	workers   int
+	queueSize int
+	queue     chan asyncEvent
+	sending   sync.WaitGroup // PublishAsync waiting for room in the queue
+	running   sync.WaitGroup // workers
+	closed    bool
+	onError   func(event interface{}, err error)
+}
+
+func newEventBus(ctx *contextManagerImpl) *eventBus {
+	return &eventBus{ctx: ctx, workers: DefaultEventWorkers, queueSize: DefaultEventQueueSize}
+}
+
+// addEventBus creates the event bus of ctx, a built-in injected into EventPublisher fields (see builtinOf).
+func (ctx *contextManagerImpl) addEventBus() *contextManagerImpl {
+	ctx.events = newEventBus(ctx)
+	return ctx
+}
+
+func (bus *eventBus) subscribe(listener *eventListener) {
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+
+	bus.listeners = append(bus.listeners, listener)
+
+	sort.SliceStable(bus.listeners, func(i, j int) bool {
+		return bus.listeners[i].order < bus.listeners[j].order
+	})
+}
+
+// subscribeBean subscribes a wired bean if it has an OnSummerEvent method of the EventListener signature.
+func (bus *eventBus) subscribeBean(item *gobean.PopulateItem) {
+	method, found := item.BeanType.MethodByName("OnSummerEvent")
+	receiver := 1 // methods of an interface type have no receiver
+
+	if item.BeanType.Kind() == reflect.Interface {
+		receiver = 0
+	}
+
+	if !found || item.Scope != "" {
+		return
+	} else if methodType := method.Type; methodType.NumIn() != receiver+2 || methodType.In(receiver) != contextType ||
+		methodType.NumOut() != 1 || methodType.Out(0) != errorType {
+		return
+	}
+
+	eventType := method.Type.In(receiver + 1)
+
+	bus.ctx.logger.Debug("event listener", beanAttrs(item, slog.String("event", eventType.String()))...)
+
+	bus.subscribe(&eventListener{
+		eventType: eventType,
+		order:     orderOf(item.Bean),
+		source:    item.Source,
+		call: func(goCtx context.Context, event reflect.Value) error {
+			if bean, err := bus.ctx.readBean(item); err != nil {
+				return err
+			} else if result := reflect.ValueOf(bean).MethodByName("OnSummerEvent").Call([]reflect.Value{reflect.ValueOf(goCtx), event}); !result[0].IsNil() {
+				return result[0].Interface().(error)
+			}
+			return nil
+		},
+	})
+}
+
+func (bus *eventBus) Publish(goCtx context.Context, event interface{}) error {
+	var errs []error
+
+	eventValue := reflect.ValueOf(event)
+
+	for current := bus; current != nil; current = current.parentBus() {
+		current.mu.RLock()
+		listeners := current.listeners
+		current.mu.RUnlock()
+
+		for _, listener := range listeners {
+			if eventValue.IsValid() && eventValue.Type().AssignableTo(listener.eventType) {
+				if err := listener.deliver(goCtx, eventValue); err != nil {
+					errs = append(errs, fmt.Errorf("%s: %w", listener.source, err))
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deliver calls the listener, a panic is turned into an error.
+func (listener *eventListener) deliver(goCtx context.Context, event reflect.Value) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("listener panic: %v", r)
+		}
+	}()
+
+	// the event may have to be converted to an interface type
+	return listener.call(goCtx, event.Convert(listener.eventType))
+}
+
+func (bus *eventBus) parentBus() *eventBus {
+	if bus.ctx.parent == nil {
+		return nil
+	}
+	return bus.ctx.parent.events
+}
+
+func (bus *eventBus) PublishAsync(goCtx context.Context, event interface{}) error {
+	bus.mu.Lock()
+
+	if bus.closed {
+		bus.mu.Unlock()
+		return ErrEventBusClosed
+	} else if bus.queue == nil {
+		bus.queue = make(chan asyncEvent, bus.queueSize)
+
+		for i := 0; i < bus.workers; i++ {
+			bus.running.Add(1)
+			go bus.work()
+		}
+	}
+
+	queue := bus.queue
+	bus.sending.Add(1) // the queue is not closed until the event is queued
+	bus.mu.Unlock()
+
+	defer bus.sending.Done()
+
+	select {
+	case queue <- asyncEvent{goCtx: goCtx, event: event}:
+		return nil
+	case <-goCtx.Done():
+		return goCtx.Err()
+	}
+}
+
+func (bus *eventBus) work() {
+	defer bus.running.Done()
+
+	for job := range bus.queue {
+		// the publisher may be long gone, only its values are kept
+		if err := bus.Publish(context.WithoutCancel(job.goCtx), job.event); err != nil {
+			bus.mu.RLock()
+			onError := bus.onError
+			bus.mu.RUnlock()
+
+			if onError != nil {
+				onError(job.event, err)
+			} else {
+				bus.ctx.logger.Warn("event listener failed", slog.String("event", fmt.Sprintf("%T", job.event)), slog.Any("error", err))
+			}
+		}
+	}
+}
+
+// shutdown rejects further async events and waits for queued ones to be delivered, or goCtx to be done.
+func (bus *eventBus) shutdown(goCtx context.Context) error {
+	bus.mu.Lock()
+
+	if bus.closed || bus.queue == nil {
+		bus.closed = true
+		bus.mu.Unlock()
+		return nil
+	}
+	bus.closed = true
+	bus.mu.Unlock()
+
+	done := make(chan struct{})
+
+	go func() {
+		bus.sending.Wait()
+		close(bus.queue)
+		bus.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-goCtx.Done():
+		return fmt.Errorf("async events not delivered: %w", goCtx.Err())
+	}
+}
+
+// Subscribe adds a listener function, for code other than beans, order works like SummerOrder.
+func Subscribe[E any](ctx ApplicationContextManager, order int, listener func(goCtx context.Context, event E) error) {
+	bus := implOf(ctx).events
+
+	bus.subscribe(&eventListener{
+		eventType: typeOf[E](),
+		order:     order,
+		source:    fmt.Sprintf("listener [%T]", listener),
+		call: func(goCtx context.Context, event reflect.Value) error {
+			return listener(goCtx, event.Interface().(E))
+		},
+	})
+}
+
+// SetEventWorkers sets the number of workers and the size of the queue for PublishAsync,
+// it should be done before the first async event.
+func (ctx *contextManagerImpl) SetEventWorkers(workers int, queueSize int) {
+	ctx.events.mu.Lock()
+	defer ctx.events.mu.Unlock()
+
+	ctx.events.workers = max(workers, 1)
+	ctx.events.queueSize = max(queueSize, 0)
+}
+
+// SetEventErrorHandler receives errors of listeners of async events, they are logged as warnings by default.
+func (ctx *contextManagerImpl) SetEventErrorHandler(handler func(event interface{}, err error)) {
+	ctx.events.mu.Lock()
+	defer ctx.events.mu.Unlock()
+
+	ctx.events.onError = handler
+}
+
+func (ctx *contextManagerImpl) Events() EventPublisher {
+	return ctx.events
+}
@@ -0,0 +1,71 @@
+package summer
+
+import (
+	"context"
+	"testing"
+)
+
+// an interface the event bus happens to implement
+type eventsPublisher interface {
+	Publish(goCtx context.Context, event interface{}) error
+}
+
+type eventsAuditLog struct {
+	received []string
+}
+
+func (a *eventsAuditLog) Publish(goCtx context.Context, event interface{}) error {
+	return nil
+}
+
+func (a *eventsAuditLog) OnSummerEvent(goCtx context.Context, event string) error {
+	a.received = append(a.received, event)
+	return nil
+}
+
+type eventsService struct {
+	Events    EventPublisher           `inject:"*"`
+	Publisher eventsPublisher          `inject:"*"`
+	Lazy      Provider[EventPublisher] `inject:"*"`
+}
+
+func TestEventPublisherIsNotABean(t *testing.T) {
+	ctx := New()
+	service := &eventsService{}
+	audit := &eventsAuditLog{}
+	ctx.Add(service, audit)
+
+	if err := ctx.PerformAutoWiringContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if service.Events != ctx.Events() {
+		t.Fatal("the event publisher of the context is not injected")
+	} else if service.Publisher != audit {
+		t.Fatal("an interface implemented by the event bus is ambiguous")
+	} else if publisher, err := service.Lazy.Get(); err != nil || publisher != ctx.Events() {
+		t.Fatalf("provider of the event publisher: %v", err)
+	}
+
+	if n := ctx.Each(func(data interface{}) {}); n != 2 {
+		t.Fatalf("Each visited %d beans, want 2", n)
+	}
+
+	if n := ctx.ForEach((*eventsPublisher)(nil), func(data interface{}) {}); n != 1 {
+		t.Fatalf("ForEach visited %d beans, want 1", n)
+	}
+
+	if graph := ctx.Graph(); len(graph.Nodes) != 2 || len(graph.Edges) != 1 {
+		t.Fatalf("unexpected graph: %+v", graph)
+	}
+
+	var publisher EventPublisher
+
+	if _, err := ctx.Get(&publisher); err != nil || publisher != ctx.Events() {
+		t.Fatalf("Get of the event publisher: %v", err)
+	}
+
+	if err := service.Events.Publish(context.Background(), "created"); err != nil || len(audit.received) != 1 {
+		t.Fatalf("event not delivered: %v", err)
+	}
+}
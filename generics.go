@@ -0,0 +1,97 @@
+package summer
+
+import (
+	"fmt"
+	"github.com/linuzilla/summer/gobean"
+	"reflect"
+)
+
+// typeOf returns the reflect.Type of T, works for interface types as well.
+func typeOf[T any]() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// modelTypeOf converts a type parameter to the "model" findByType expects:
+// interface stays as is, "pointer to structure" becomes the structure.
+func modelTypeOf(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
+
+func implOf(ctx ApplicationContextManager) *contextManagerImpl {
+	if impl, ok := ctx.(*contextManagerImpl); ok {
+		return impl
+	}
+	panic(fmt.Errorf("unsupported application context: %T", ctx))
+}
+
+func castBean[T any](ctx *contextManagerImpl, item *gobean.PopulateItem, beanName string) (T, error) {
+	var zero T
+
+	if bean, err := ctx.readBean(item); err != nil {
+		return zero, err
+	} else if typed, ok := bean.(T); ok {
+		return typed, nil
+	} else {
+		return zero, &LookupError{Type: typeOf[T](), Name: beanName, Matched: 1, Err: ErrWrongType}
+	}
+}
+
+// Get retrieves the only wired bean matching T, T should be an interface or a "pointer to structure".
+//
+//	cat, err := summer.Get[sub.ICat](ctx)
+func Get[T any](ctx ApplicationContextManager) (T, error) {
+	impl := implOf(ctx)
+
+	unlock := impl.readLock()
+	item, err := impl.resolveByType(modelTypeOf(typeOf[T]()))
+	unlock()
+
+	if err != nil {
+		var zero T
+		return zero, err
+	} else {
+		return castBean[T](impl, item, "")
+	}
+}
+
+// MustGet is like Get but panics if the bean can not be resolved.
+func MustGet[T any](ctx ApplicationContextManager) T {
+	if bean, err := Get[T](ctx); err != nil {
+		panic(err)
+	} else {
+		return bean
+	}
+}
+
+// GetByName retrieves a wired bean by name and converts it to T.
+func GetByName[T any](ctx ApplicationContextManager, beanName string) (T, error) {
+	impl := implOf(ctx)
+
+	unlock := impl.readLock()
+	item, _, err := impl.getBeanByName(beanName)
+	unlock()
+
+	if err != nil {
+		var zero T
+		return zero, err
+	} else {
+		return castBean[T](impl, item, beanName)
+	}
+}
+
+// All returns every wired bean matching T, in registration order.
+func All[T any](ctx ApplicationContextManager) []T {
+	var beans []T
+
+	impl := implOf(ctx)
+
+	for _, item := range impl.wiredItems(modelTypeOf(typeOf[T]())) {
+		if bean, err := castBean[T](impl, item, item.Name); err == nil {
+			beans = append(beans, bean)
+		}
+	}
+	return beans
+}
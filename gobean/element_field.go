@@ -10,15 +10,45 @@ type ElementField struct {
 	StructField reflect.StructField
 	FieldValue  reflect.Value
 	Wired       bool
-	TagValue    string
+	Tag         *InjectTag
 	Index       int
+	Targets     []*PopulateItem // beans injected, once wired
+}
+
+// IsCollection tells whether the field receives every matching bean, as a slice or a map keyed by bean name
+func (elemField *ElementField) IsCollection() bool {
+	kind := elemField.StructField.Type.Kind()
+	return elemField.Tag.ByType && (kind == reflect.Slice || kind == reflect.Map)
+}
+
+// ModelType is the type to be matched when injecting by type:
+// an interface, the structure of a pointer, or the element of a collection.
+func (elemField *ElementField) ModelType() reflect.Type {
+	modelType := elemField.StructField.Type
+
+	if elemField.IsCollection() {
+		modelType = modelType.Elem()
+	}
+
+	if modelType.Kind() == reflect.Ptr {
+		modelType = modelType.Elem()
+	}
+	return modelType
 }
 
 func (elemField *ElementField) FullName(injectionTag string) string {
+	if elemField.Parent.IsProvider() {
+		return fmt.Sprintf("provider: %s ( %s %s `%s:\"%s\"` )",
+			elemField.Parent.Constructor.Type().String(),
+			elemField.StructField.Name,
+			elemField.StructField.Type.String(),
+			injectionTag,
+			elemField.Tag.Raw)
+	}
 	return fmt.Sprintf("struct: %s [ %s %s `%s:\"%s\"` ]",
 		elemField.Parent.BeanType.String(),
 		elemField.StructField.Name,
 		elemField.StructField.Type.String(),
 		injectionTag,
-		elemField.TagValue)
+		elemField.Tag.Raw)
 }
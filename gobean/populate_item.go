@@ -6,6 +6,8 @@ import (
 	"reflect"
 	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 type PopulateItem struct {
@@ -17,13 +19,88 @@ type PopulateItem struct {
 	Fields     []*ElementField
 	WiredCount int
 	Source     string
+	// "file:line" the bean was added
+	Location string
+	// provider function, the Bean will be created by calling it once all arguments are injected
+	Constructor reflect.Value
+	// bean name, empty if added without a name
+	Name string
+	// empty for singleton, otherwise the Bean is only a template and instances are created by the scope
+	Scope string
+	// fields to be filled with properties
+	Values []*ValueField
+	// injection points of the structure, nil for a provider
+	Meta *TypeMeta
+	// created (if by a provider) and post constructed on first access, only for singletons
+	Lazy bool
+	lazy lazyState
+}
+
+type lazyState struct {
+	once sync.Once
+	done atomic.Bool
+	err  error
+}
+
+// InitLazy calls init once for a lazy bean, later calls return the same error.
+func (item *PopulateItem) InitLazy(init func() error) error {
+	item.lazy.once.Do(func() {
+		item.lazy.err = init()
+		item.lazy.done.Store(true)
+	})
+	return item.lazy.err
+}
+
+// LazyInitialized tells whether InitLazy has been called, successfully or not.
+func (item *PopulateItem) LazyInitialized() bool {
+	return item.lazy.done.Load()
+}
+
+// IsReady tells whether every field is wired and the bean, unless scoped or lazy, has been created.
+func (item *PopulateItem) IsReady() bool {
+	return item.WiredCount == len(item.Fields) && (item.Lazy || item.Scope != "" || item.BeanValue.IsValid())
 }
 
 func (item *PopulateItem) CheckIsWired() bool {
-	item.Wired = item.WiredCount == len(item.Fields)
+	item.Wired = item.IsReady()
 	return item.Wired
 }
 
+// NewInstance creates a fresh and not yet wired copy of a scoped item, the Bean is copied from the template
+// (or left to the provider function), so fields not tagged for injection keep their values.
+func (item *PopulateItem) NewInstance() *PopulateItem {
+	instance := &PopulateItem{
+		BeanType:    item.BeanType,
+		Constructor: item.Constructor,
+		Name:        item.Name,
+		Source:      item.Source,
+		Location:    item.Location,
+	}
+
+	if item.IsProvider() {
+		for _, elemField := range item.Fields {
+			instance.Fields = append(instance.Fields, &ElementField{
+				Parent:      instance,
+				StructField: elemField.StructField,
+				FieldValue:  reflect.New(elemField.StructField.Type).Elem(),
+				Index:       elemField.Index,
+				Tag:         elemField.Tag,
+			})
+		}
+		return instance
+	}
+
+	beanValue := reflect.New(item.BeanType.Elem())
+	beanValue.Elem().Set(item.BeanValue.Elem())
+
+	instance.Bean = beanValue.Interface()
+	instance.BeanValue = beanValue
+
+	// properties are copied from the template, no need to bind them again
+	instance.bindFields(item.Meta, false)
+	return instance
+}
+
 func (item *PopulateItem) String() string {
 	var str strings.Builder
 
@@ -57,7 +134,7 @@ func (item *PopulateItem) String() string {
 	return str.String()
 }
 
-func New(bean interface{}, skip int, injectionTag string) (*PopulateItem, error) {
+func New(bean interface{}, skip int, injectionTag string, valueTag string) (*PopulateItem, error) {
 	function, file, line, _ := runtime.Caller(skip)
 
 	beanType := reflect.TypeOf(bean)
@@ -71,48 +148,13 @@ func New(bean interface{}, skip int, injectionTag string) (*PopulateItem, error)
 		BeanValue:  reflect.ValueOf(bean),
 		WiredCount: 0,
 		Source:     fmt.Sprintf("Bean [%s] add via file: [%s:%d], function: [%s]", beanType.String(), utils.Basename(file), line, runtime.FuncForPC(function).Name()),
+		Location:   fmt.Sprintf("%s:%d", utils.Basename(file), line),
 	}
 
-	var elementFieldList []*ElementField
-
-	if newList, err := item.retrieveFieldsRecursively(injectionTag, reflect.ValueOf(item.Bean).Elem(), elementFieldList); err != nil {
-		return item, fmt.Errorf("%s\n%s\n%v", item.Source, item.String(), err)
+	if meta, err := DescribeType(beanType.Elem(), injectionTag, valueTag); err != nil {
+		return item, fmt.Errorf("%s\n%s\n%w", item.Source, item.String(), err)
 	} else {
-		item.Fields = newList
-		item.CheckIsWired()
+		item.bindFields(meta, true)
 		return item, nil
 	}
 }
-
-func (item *PopulateItem) retrieveFieldsRecursively(injectionTag string, elemValue reflect.Value, prevList []*ElementField) (newList []*ElementField, err error) {
-	elemType := elemValue.Type()
-	newList = prevList
-
-	for i := 0; i < elemValue.NumField(); i++ {
-		typeField := elemType.Field(i)
-
-		if tag := typeField.Tag.Get(injectionTag); len(tag) > 0 {
-			valueField := elemValue.Field(i)
-
-			newElementField := &ElementField{
-				Parent:      item,
-				Wired:       false,
-				StructField: elemValue.Type().Field(i),
-				FieldValue:  elemValue.Field(i),
-				Index:       i,
-				TagValue:    tag,
-			}
-
-			if tag == `+` {
-				if !newElementField.StructField.Anonymous {
-					return newList, fmt.Errorf(`ERROR: "+" type of inject should only be used in anonymous field`)
-				} else {
-					newList, err = item.retrieveFieldsRecursively(injectionTag, valueField, newList)
-				}
-			} else {
-				newList = append(newList, newElementField)
-			}
-		}
-	}
-	return newList, err
-}
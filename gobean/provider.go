@@ -0,0 +1,98 @@
+package gobean
+
+import (
+	"fmt"
+	"github.com/linuzilla/summer/utils"
+	"reflect"
+	"runtime"
+)
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// NewProvider creates a PopulateItem from a provider function, such as
+//
+//	func(a *Rabbit, c sub.ICat) (*Dog, error)
+//
+// every argument becomes an ElementField, matched by type unless a qualifier is given,
+// a qualifier is parsed the same way as an injection tag, "kitty" or "name=kitty,optional" for example.
+func NewProvider(constructor interface{}, qualifiers []string, skip int) (*PopulateItem, error) {
+	function, file, line, _ := runtime.Caller(skip)
+
+	fnValue := reflect.ValueOf(constructor)
+	fnType := fnValue.Type()
+
+	if fnType.Kind() != reflect.Func {
+		return nil, fmt.Errorf("provider should be a function, got [%s]", fnType)
+	} else if fnType.IsVariadic() {
+		return nil, fmt.Errorf("provider [%s] should not be variadic", fnType)
+	} else if fnType.NumOut() < 1 || fnType.NumOut() > 2 || (fnType.NumOut() == 2 && fnType.Out(1) != errorType) {
+		return nil, fmt.Errorf("provider [%s] should return (bean) or (bean, error)", fnType)
+	} else if kind := fnType.Out(0).Kind(); kind != reflect.Ptr && kind != reflect.Interface {
+		return nil, fmt.Errorf("provider [%s] should return a pointer or an interface", fnType)
+	} else if len(qualifiers) > fnType.NumIn() {
+		return nil, fmt.Errorf("provider [%s] has %d arguments but %d qualifiers given", fnType, fnType.NumIn(), len(qualifiers))
+	}
+
+	beanType := fnType.Out(0)
+
+	item := &PopulateItem{
+		Wired:       false,
+		BeanType:    beanType,
+		WiredCount:  0,
+		Constructor: fnValue,
+		Source:      fmt.Sprintf("Provider [%s] add via file: [%s:%d], function: [%s]", beanType.String(), utils.Basename(file), line, runtime.FuncForPC(function).Name()),
+		Location:    fmt.Sprintf("%s:%d", utils.Basename(file), line),
+	}
+
+	for i := 0; i < fnType.NumIn(); i++ {
+		tagValue := `*`
+
+		if i < len(qualifiers) && qualifiers[i] != "" {
+			tagValue = qualifiers[i]
+		}
+
+		tag, err := ParseTag(tagValue)
+
+		if err != nil {
+			return nil, fmt.Errorf("provider [%s] argument %d: %w", fnType, i, err)
+		} else if tag.Embedded {
+			return nil, fmt.Errorf("provider [%s] argument %d: '+' is not allowed", fnType, i)
+		}
+
+		item.Fields = append(item.Fields, &ElementField{
+			Parent:      item,
+			Wired:       false,
+			StructField: reflect.StructField{Name: fmt.Sprintf("arg%d", i), Type: fnType.In(i)},
+			FieldValue:  reflect.New(fnType.In(i)).Elem(),
+			Index:       i,
+			Tag:         tag,
+		})
+	}
+	return item, nil
+}
+
+// IsProvider tells whether the bean is created by a provider function
+func (item *PopulateItem) IsProvider() bool {
+	return item.Constructor.IsValid()
+}
+
+// Construct calls the provider function with injected arguments, it should only be called once all arguments are wired.
+func (item *PopulateItem) Construct() error {
+	args := make([]reflect.Value, len(item.Fields))
+
+	for i, elemField := range item.Fields {
+		args[i] = elemField.FieldValue
+	}
+
+	results := item.Constructor.Call(args)
+
+	if len(results) == 2 && !results[1].IsNil() {
+		return results[1].Interface().(error)
+	} else if bean := results[0]; bean.IsNil() {
+		return fmt.Errorf("provider returned nil")
+	} else {
+		item.Bean = bean.Interface()
+		item.BeanValue = reflect.ValueOf(item.Bean)
+		return nil
+	}
+}
@@ -0,0 +1,72 @@
+package gobean
+
+import (
+	"errors"
+	"testing"
+)
+
+type providedBean struct{}
+
+func TestNewProvider(t *testing.T) {
+	item, err := NewProvider(func(a *providedBean, b interface{}) (*providedBean, error) { return a, nil }, []string{"", "name=b,optional"}, 0)
+
+	if err != nil {
+		t.Fatal(err)
+	} else if !item.IsProvider() || len(item.Fields) != 2 {
+		t.Fatalf("unexpected item: %+v", item)
+	}
+
+	if tag := item.Fields[0].Tag; !tag.ByType {
+		t.Errorf("argument 0: %+v", tag)
+	}
+
+	if tag := item.Fields[1].Tag; tag.Name != "b" || !tag.Optional {
+		t.Errorf("argument 1: %+v", tag)
+	}
+}
+
+func TestNewProviderSignatures(t *testing.T) {
+	for name, constructor := range map[string]interface{}{
+		"not a function":   &providedBean{},
+		"variadic":         func(beans ...*providedBean) *providedBean { return nil },
+		"no result":        func() {},
+		"second not error": func() (*providedBean, int) { return nil, 0 },
+		"three results":    func() (*providedBean, error, error) { return nil, nil, nil },
+		"not a pointer":    func() providedBean { return providedBean{} },
+	} {
+		if _, err := NewProvider(constructor, nil, 0); err == nil {
+			t.Errorf("%s: accepted", name)
+		}
+	}
+
+	if _, err := NewProvider(func(a *providedBean) *providedBean { return a }, []string{"a", "b"}, 0); err == nil {
+		t.Error("more qualifiers than arguments: accepted")
+	}
+
+	if _, err := NewProvider(func(a *providedBean) *providedBean { return a }, []string{"+"}, 0); err == nil {
+		t.Error("'+' qualifier: accepted")
+	}
+}
+
+func TestConstruct(t *testing.T) {
+	errBoom := errors.New("boom")
+	bean := &providedBean{}
+
+	item, _ := NewProvider(func() (*providedBean, error) { return bean, nil }, nil, 0)
+
+	if err := item.Construct(); err != nil || item.Bean != bean {
+		t.Fatalf("got %v, %v", item.Bean, err)
+	}
+
+	item, _ = NewProvider(func() (*providedBean, error) { return nil, errBoom }, nil, 0)
+
+	if err := item.Construct(); !errors.Is(err, errBoom) || item.Bean != nil {
+		t.Fatalf("got %v, %v", item.Bean, err)
+	}
+
+	item, _ = NewProvider(func() *providedBean { return nil }, nil, 0)
+
+	if err := item.Construct(); err == nil {
+		t.Fatal("nil accepted")
+	}
+}
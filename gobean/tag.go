@@ -0,0 +1,78 @@
+package gobean
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrMalformedTag is returned by ParseTag for a tag not following the grammar of InjectTag
+var ErrMalformedTag = errors.New("malformed tag")
+
+// InjectTag is the parsed value of an injection tag, the grammar is a comma separated list:
+//
+//	`inject:"*"`                        by type
+//	`inject:"kitty"`                    by name, same as `inject:"name=kitty"`
+//	`inject:"+"`                        anonymous field, look for injection points inside it
+//	`inject:"*,optional"`               left untouched if there is no such bean
+//	`inject:"name=mailer,fallback=noop"` use bean "noop" if there is no bean named "mailer"
+type InjectTag struct {
+	Raw      string // the tag as written
+	ByType   bool
+	Name     string
+	Embedded bool
+	Optional bool
+	Fallback string
+}
+
+func ParseTag(value string) (*InjectTag, error) {
+	tag := &InjectTag{Raw: value}
+
+	for i, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		key, val, hasValue := strings.Cut(part, "=")
+		key = strings.TrimSpace(key)
+		val = strings.TrimSpace(val)
+
+		switch {
+		case part == "":
+			return nil, fmt.Errorf("%w %q: empty element", ErrMalformedTag, value)
+
+		case part == `*` && i == 0:
+			tag.ByType = true
+
+		case part == `+` && i == 0:
+			tag.Embedded = true
+
+		case key == "name" && hasValue:
+			if val == "" || tag.Name != "" || tag.ByType {
+				return nil, fmt.Errorf("%w %q: name should be given once, not along with '*'", ErrMalformedTag, value)
+			}
+			tag.Name = val
+
+		case key == "fallback" && hasValue:
+			if val == "" || tag.Fallback != "" {
+				return nil, fmt.Errorf("%w %q: fallback should be given once", ErrMalformedTag, value)
+			}
+			tag.Fallback = val
+
+		case part == "optional":
+			tag.Optional = true
+
+		case i == 0 && !hasValue && !strings.ContainsAny(part, "*+ "):
+			tag.Name = part
+
+		default:
+			return nil, fmt.Errorf("%w %q: unknown element '%s'", ErrMalformedTag, value, part)
+		}
+	}
+
+	switch {
+	case tag.Embedded && (tag.Optional || tag.Fallback != "" || tag.Name != ""):
+		return nil, fmt.Errorf("%w %q: '+' does not take any option", ErrMalformedTag, value)
+
+	case !tag.Embedded && !tag.ByType && tag.Name == "":
+		return nil, fmt.Errorf("%w %q: either '*' or a bean name is required", ErrMalformedTag, value)
+	}
+	return tag, nil
+}
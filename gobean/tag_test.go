@@ -0,0 +1,73 @@
+package gobean
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseTag(t *testing.T) {
+	for _, test := range []struct {
+		value string
+		want  InjectTag
+	}{
+		{"*", InjectTag{ByType: true}},
+		{"kitty", InjectTag{Name: "kitty"}},
+		{"name=kitty", InjectTag{Name: "kitty"}},
+		{" kitty , optional ", InjectTag{Name: "kitty", Optional: true}},
+		{"*,optional", InjectTag{ByType: true, Optional: true}},
+		{"name=mailer,fallback=noop", InjectTag{Name: "mailer", Fallback: "noop"}},
+		{"*,fallback=noop,optional", InjectTag{ByType: true, Fallback: "noop", Optional: true}},
+		{"+", InjectTag{Embedded: true}},
+	} {
+		tag, err := ParseTag(test.value)
+
+		if err != nil {
+			t.Errorf("%q: %v", test.value, err)
+			continue
+		}
+
+		test.want.Raw = test.value
+
+		if *tag != test.want {
+			t.Errorf("%q: got %+v, want %+v", test.value, *tag, test.want)
+		}
+	}
+}
+
+func TestParseMalformedTag(t *testing.T) {
+	for _, value := range []string{
+		"",
+		"*,",
+		"name=",
+		"*,name=kitty",
+		"name=a,name=b",
+		"fallback=a",
+		"kitty,fallback=a,fallback=b",
+		"optional",
+		"kitty,*",
+		"+,optional",
+		"+,name=kitty",
+		"color=red",
+		"two words",
+	} {
+		if tag, err := ParseTag(value); !errors.Is(err, ErrMalformedTag) {
+			t.Errorf("%q: got %+v, %v, want %v", value, tag, err, ErrMalformedTag)
+		}
+	}
+}
+
+type malformedBean struct {
+	Cat interface{} `inject:"*,name=kitty"`
+}
+
+func TestMalformedTagOfBean(t *testing.T) {
+	if _, err := New(&malformedBean{}, 0, "inject", "value"); !errors.Is(err, ErrMalformedTag) {
+		t.Errorf("got %v, want %v", err, ErrMalformedTag)
+	}
+}
+
+func TestMalformedQualifierOfProvider(t *testing.T) {
+	if _, err := NewProvider(func(cat interface{}) *malformedBean { return nil }, []string{"kitty,"}, 0); !errors.Is(err, ErrMalformedTag) {
+		t.Errorf("got %v, want %v", err, ErrMalformedTag)
+	}
+}
@@ -0,0 +1,132 @@
+package gobean
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// TypeMeta describes the injection points of a structure type, it is built once and shared by every bean of the type.
+type TypeMeta struct {
+	Type    reflect.Type // the structure
+	Fields  []*FieldMeta // fields tagged for injection, those of embedded "+" structures included
+	Values  []*FieldMeta // fields to be filled with properties
+	setters sync.Map     // setter name -> reflect.Method, or nil if there is no such method
+}
+
+// FieldMeta is a field of a structure type tagged for injection (or property).
+type FieldMeta struct {
+	StructField reflect.StructField
+	IndexPath   []int      // for reflect.Value.FieldByIndex, through embedded structures
+	Tag         *InjectTag // nil for a property
+	TagValue    string
+}
+
+type typeMetaKey struct {
+	structType   reflect.Type
+	injectionTag string
+	valueTag     string
+}
+
+var typeMetaCache sync.Map // typeMetaKey -> *TypeMeta
+
+// DescribeType returns the injection points of a structure type, tags are only parsed the first time.
+// valueTag can be empty if properties are not needed.
+func DescribeType(structType reflect.Type, injectionTag string, valueTag string) (*TypeMeta, error) {
+	key := typeMetaKey{structType: structType, injectionTag: injectionTag, valueTag: valueTag}
+
+	if cached, found := typeMetaCache.Load(key); found {
+		return cached.(*TypeMeta), nil
+	}
+
+	meta := &TypeMeta{Type: structType}
+
+	if structType.Kind() == reflect.Struct {
+		if err := meta.collect(injectionTag, valueTag, structType, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	cached, _ := typeMetaCache.LoadOrStore(key, meta)
+	return cached.(*TypeMeta), nil
+}
+
+func (meta *TypeMeta) collect(injectionTag string, valueTag string, structType reflect.Type, indexPath []int) error {
+	for i := 0; i < structType.NumField(); i++ {
+		typeField := structType.Field(i)
+		fieldPath := append(append([]int(nil), indexPath...), i)
+
+		if valueTag == "" {
+		} else if tagValue := typeField.Tag.Get(valueTag); len(tagValue) > 0 {
+			if len(typeField.Tag.Get(injectionTag)) > 0 {
+				return fmt.Errorf("%s.%s: both `%s` and `%s` tag given", structType.String(), typeField.Name, injectionTag, valueTag)
+			}
+
+			meta.Values = append(meta.Values, &FieldMeta{StructField: typeField, IndexPath: fieldPath, TagValue: tagValue})
+			continue
+		}
+
+		if tagValue := typeField.Tag.Get(injectionTag); len(tagValue) > 0 {
+			tag, err := ParseTag(tagValue)
+
+			if err != nil {
+				return fmt.Errorf("%s.%s: %w", structType.String(), typeField.Name, err)
+			}
+
+			if !tag.Embedded {
+				meta.Fields = append(meta.Fields, &FieldMeta{StructField: typeField, IndexPath: fieldPath, Tag: tag, TagValue: tagValue})
+			} else if !typeField.Anonymous {
+				return fmt.Errorf(`ERROR: "+" type of inject should only be used in anonymous field`)
+			} else if err := meta.collect(injectionTag, valueTag, typeField.Type, fieldPath); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Setter looks up (once) a method of the pointer to the structure, such as "SetCat".
+func (meta *TypeMeta) Setter(methodName string) (reflect.Method, bool) {
+	if cached, found := meta.setters.Load(methodName); found {
+		method, ok := cached.(reflect.Method)
+		return method, ok
+	}
+
+	if method, found := reflect.PointerTo(meta.Type).MethodByName(methodName); found {
+		meta.setters.Store(methodName, method)
+		return method, true
+	}
+
+	meta.setters.Store(methodName, nil)
+	return reflect.Method{}, false
+}
+
+// bindFields creates fields of the item from the type meta, values are skipped unless withValues.
+func (item *PopulateItem) bindFields(meta *TypeMeta, withValues bool) {
+	elemValue := item.BeanValue.Elem()
+
+	item.Meta = meta
+
+	for _, field := range meta.Fields {
+		item.Fields = append(item.Fields, &ElementField{
+			Parent:      item,
+			Wired:       false,
+			StructField: field.StructField,
+			FieldValue:  elemValue.FieldByIndex(field.IndexPath),
+			Index:       field.IndexPath[len(field.IndexPath)-1],
+			Tag:         field.Tag,
+		})
+	}
+
+	if withValues {
+		for _, field := range meta.Values {
+			item.Values = append(item.Values, &ValueField{
+				Parent:      item,
+				StructField: field.StructField,
+				FieldValue:  elemValue.FieldByIndex(field.IndexPath),
+				TagValue:    field.TagValue,
+				Index:       field.IndexPath[len(field.IndexPath)-1],
+			})
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package gobean
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ValueField is a field to be filled with a property, such as
+//
+//	Port int `value:"${server.port:8080}"`
+type ValueField struct {
+	Parent      *PopulateItem
+	StructField reflect.StructField
+	FieldValue  reflect.Value
+	TagValue    string
+	Index       int
+	Injected    bool
+}
+
+func (valueField *ValueField) FullName(valueTag string) string {
+	return fmt.Sprintf("struct: %s [ %s %s `%s:\"%s\"` ]",
+		valueField.Parent.BeanType.String(),
+		valueField.StructField.Name,
+		valueField.StructField.Type.String(),
+		valueTag,
+		valueField.TagValue)
+}
@@ -0,0 +1,211 @@
+package summer
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/linuzilla/summer/gobean"
+	"io"
+	"strings"
+)
+
+// GraphNode is a bean of the dependency graph.
+type GraphNode struct {
+	ID     string `json:"id"` // bean name, or bean type if added without a name
+	Type   string `json:"type"`
+	Name   string `json:"name,omitempty"`
+	Scope  string `json:"scope,omitempty"`
+	Source string `json:"source"`
+	Wired  bool   `json:"wired"`
+	Parent bool   `json:"parent,omitempty"` // a bean of an ancestor context
+}
+
+// GraphEdge is an injection point, To is empty if no bean can be found for it (a dangling edge).
+type GraphEdge struct {
+	From       string `json:"from"`
+	To         string `json:"to,omitempty"`
+	Field      string `json:"field"`
+	FieldType  string `json:"fieldType"`
+	Tag        string `json:"tag"`
+	ByName     bool   `json:"byName"`
+	Collection bool   `json:"collection,omitempty"`
+	Optional   bool   `json:"optional,omitempty"`
+	Wired      bool   `json:"wired"`
+}
+
+// Graph is how beans are wired, or would be wired if autowiring has not been done yet.
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+func (ctx *contextManagerImpl) Graph() *Graph {
+	defer ctx.readLock()()
+
+	graph := &Graph{Nodes: []GraphNode{}, Edges: []GraphEdge{}}
+	ids := map[*gobean.PopulateItem]string{}
+	used := map[string]int{}
+
+	for e := ctx.items.Front(); e != nil; e = e.Next() {
+		item := e.Value.(*gobean.PopulateItem)
+		id := graphNodeName(item)
+
+		if used[id]++; used[id] > 1 {
+			id = fmt.Sprintf("%s#%d", id, used[id])
+		}
+		ids[item] = id
+
+		graph.Nodes = append(graph.Nodes, GraphNode{
+			ID:     id,
+			Type:   item.BeanType.String(),
+			Name:   item.Name,
+			Scope:  item.Scope,
+			Source: item.Source,
+			Wired:  item.Wired,
+		})
+	}
+
+	for e := ctx.items.Front(); e != nil; e = e.Next() {
+		item := e.Value.(*gobean.PopulateItem)
+
+		for _, elemField := range item.Fields {
+			if ctx.isBuiltinField(elemField) { // not a bean, see builtinOf
+				continue
+			}
+
+			edge := GraphEdge{
+				From:       ids[item],
+				Field:      elemField.StructField.Name,
+				FieldType:  elemField.StructField.Type.String(),
+				Tag:        elemField.Tag.Raw,
+				ByName:     !elemField.Tag.ByType,
+				Collection: elemField.IsCollection(),
+				Optional:   elemField.Tag.Optional,
+				Wired:      elemField.Wired,
+			}
+
+			targets := elemField.Targets
+
+			if !elemField.Wired || isProviderField(elemField) {
+				targets = ctx.dependencyTargets(item, elemField)
+			}
+
+			if len(targets) == 0 && !edge.Optional && !edge.Collection {
+				graph.Edges = append(graph.Edges, edge) // dangling
+			}
+
+			for _, target := range targets {
+				if _, found := ids[target]; !found { // from an ancestor context
+					ids[target] = "parent:" + graphNodeName(target)
+					graph.Nodes = append(graph.Nodes, GraphNode{
+						ID:     ids[target],
+						Type:   target.BeanType.String(),
+						Name:   target.Name,
+						Scope:  target.Scope,
+						Source: target.Source,
+						Wired:  target.Wired,
+						Parent: true,
+					})
+				}
+				edge.To = ids[target]
+				graph.Edges = append(graph.Edges, edge)
+			}
+		}
+	}
+	return graph
+}
+
+func (graph *Graph) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(graph)
+}
+
+// WriteDOT writes the graph in Graphviz DOT language, dangling edges point to red "missing" nodes.
+func (graph *Graph) WriteDOT(w io.Writer) error {
+	var str strings.Builder
+
+	str.WriteString("digraph summer {\n  rankdir=LR;\n  node [shape=box];\n")
+
+	for _, node := range graph.Nodes {
+		label := node.Type
+
+		if node.Name != "" {
+			label = node.Name + "\\n" + node.Type
+		}
+
+		style := ""
+
+		if !node.Wired {
+			style = ", style=dashed"
+		}
+		str.WriteString(fmt.Sprintf("  %s [label=%s%s];\n", dotQuote(node.ID), dotQuote(label), style))
+	}
+
+	for i, edge := range graph.Edges {
+		label := dotQuote(fmt.Sprintf("%s `%s`", edge.Field, edge.Tag))
+
+		if edge.To == "" {
+			missing := dotQuote(fmt.Sprintf("missing#%d", i))
+			str.WriteString(fmt.Sprintf("  %s [label=%s, shape=none, fontcolor=red];\n", missing, dotQuote("? "+edge.FieldType)))
+			str.WriteString(fmt.Sprintf("  %s -> %s [label=%s, color=red, style=dashed];\n", dotQuote(edge.From), missing, label))
+		} else if edge.ByName {
+			str.WriteString(fmt.Sprintf("  %s -> %s [label=%s, style=bold];\n", dotQuote(edge.From), dotQuote(edge.To), label))
+		} else {
+			str.WriteString(fmt.Sprintf("  %s -> %s [label=%s];\n", dotQuote(edge.From), dotQuote(edge.To), label))
+		}
+	}
+
+	str.WriteString("}\n")
+	_, err := io.WriteString(w, str.String())
+	return err
+}
+
+// WriteMermaid writes the graph as a Mermaid flowchart, dangling edges point to "missing" nodes.
+func (graph *Graph) WriteMermaid(w io.Writer) error {
+	var str strings.Builder
+
+	mermaidIDs := map[string]string{}
+
+	str.WriteString("flowchart LR\n")
+
+	for i, node := range graph.Nodes {
+		mermaidIDs[node.ID] = fmt.Sprintf("n%d", i)
+		label := mermaidEscape(node.Type)
+
+		if node.Name != "" {
+			label = mermaidEscape(node.Name) + "<br/>" + label
+		}
+		str.WriteString(fmt.Sprintf("  n%d[\"%s\"]\n", i, label))
+	}
+
+	for i, edge := range graph.Edges {
+		label := mermaidEscape(edge.Field + " " + edge.Tag)
+
+		if edge.To == "" {
+			str.WriteString(fmt.Sprintf("  %s -. \"%s\" .-> m%d[\"? %s\"]\n", mermaidIDs[edge.From], label, i, mermaidEscape(edge.FieldType)))
+			str.WriteString(fmt.Sprintf("  style m%d stroke:#f00,color:#f00\n", i))
+		} else if edge.ByName {
+			str.WriteString(fmt.Sprintf("  %s == \"%s\" ==> %s\n", mermaidIDs[edge.From], label, mermaidIDs[edge.To]))
+		} else {
+			str.WriteString(fmt.Sprintf("  %s -- \"%s\" --> %s\n", mermaidIDs[edge.From], label, mermaidIDs[edge.To]))
+		}
+	}
+
+	_, err := io.WriteString(w, str.String())
+	return err
+}
+
+func graphNodeName(item *gobean.PopulateItem) string {
+	if item.Name != "" {
+		return item.Name
+	}
+	return item.BeanType.String()
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`"`, `\"`).Replace(s) + `"`
+}
+
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;").Replace(s)
+}
@@ -0,0 +1,246 @@
+package summer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/linuzilla/summer/gobean"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	DefaultHealthTimeout  = 5 * time.Second
+	DefaultHealthCacheTTL = time.Second
+)
+
+// Status of a HealthIndicator, or the composite status of all of them.
+type Status string
+
+const (
+	StatusUp       Status = "UP"
+	StatusDegraded Status = "DEGRADED" // working, but not at its best
+	StatusDown     Status = "DOWN"
+)
+
+// kind of like "HealthIndicator" in Spring Boot, every wired bean implementing it is checked by the HealthRegistry.
+// goCtx is done once the timeout of the registry is reached, a bean failing to return in time is DOWN.
+type HealthIndicator interface {
+	Health(goCtx context.Context) Status
+}
+
+// ComponentHealth is the outcome of a HealthIndicator.
+type ComponentHealth struct {
+	Status   Status `json:"status"`
+	Error    string `json:"error,omitempty"` // timeout or panic
+	Duration string `json:"duration"`
+}
+
+// HealthReport is the composite status: DOWN if any bean is DOWN, DEGRADED if any is DEGRADED, UP otherwise.
+type HealthReport struct {
+	Status     Status                     `json:"status"`
+	Reason     string                     `json:"reason,omitempty"` // why the context itself is not ready
+	Components map[string]ComponentHealth `json:"components,omitempty"`
+	CheckedAt  time.Time                  `json:"checkedAt"`
+}
+
+// HealthRegistry is built into every context, aggregating the HealthIndicator beans, those of the ancestors
+// included. Inject it with `inject:"*"` or call Health() on the context, it is not a bean: Each, ForEach and Graph
+// do not list it.
+type HealthRegistry struct {
+	ctx      *contextManagerImpl
+	mu       sync.Mutex // one check at a time, callers meanwhile wait for its report
+	timeout  atomic.Int64
+	cacheTTL atomic.Int64
+	cached   *HealthReport
+	closing  atomic.Bool
+}
+
+func newHealthRegistry(ctx *contextManagerImpl) *HealthRegistry {
+	registry := &HealthRegistry{ctx: ctx}
+
+	registry.timeout.Store(int64(DefaultHealthTimeout))
+	registry.cacheTTL.Store(int64(DefaultHealthCacheTTL))
+
+	ctx.events.subscribe(&eventListener{
+		eventType: typeOf[ContextClosing](),
+		source:    "health registry",
+		call: func(goCtx context.Context, event reflect.Value) error {
+			if event.Interface().(ContextClosing).Context == ApplicationContextManager(ctx) {
+				registry.closing.Store(true)
+			}
+			return nil
+		},
+	})
+	return registry
+}
+
+// addHealthRegistry creates the health registry of ctx, a built-in injected into *HealthRegistry fields
+// (see builtinOf), after the event bus it listens to.
+func (ctx *contextManagerImpl) addHealthRegistry() *contextManagerImpl {
+	ctx.health = newHealthRegistry(ctx)
+	return ctx
+}
+
+func (ctx *contextManagerImpl) Health() *HealthRegistry {
+	return ctx.health
+}
+
+// SetTimeout limits the time every HealthIndicator is given, DefaultHealthTimeout by default.
+func (registry *HealthRegistry) SetTimeout(timeout time.Duration) {
+	registry.timeout.Store(int64(timeout))
+}
+
+// SetCacheTTL keeps a report for a while, so probes do not hammer the beans, DefaultHealthCacheTTL by default.
+func (registry *HealthRegistry) SetCacheTTL(ttl time.Duration) {
+	registry.cacheTTL.Store(int64(ttl))
+}
+
+// Check returns the composite status, the beans are checked in parallel unless the last report is still fresh.
+// The context itself is DOWN until it is wired, and once it is closing.
+func (registry *HealthRegistry) Check(goCtx context.Context) HealthReport {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if reason := registry.notReady(); reason != "" {
+		return HealthReport{Status: StatusDown, Reason: reason, CheckedAt: time.Now()}
+	} else if cached := registry.cached; cached != nil && time.Since(cached.CheckedAt) < time.Duration(registry.cacheTTL.Load()) {
+		return *cached
+	}
+
+	items := registry.ctx.wiredItems(typeOf[HealthIndicator]())
+
+	components := make([]ComponentHealth, len(items))
+
+	// the report is shared by every caller, a caller gone away does not make the beans DOWN
+	goCtx = context.WithoutCancel(goCtx)
+
+	var wg sync.WaitGroup
+
+	for i, item := range items {
+		wg.Add(1)
+
+		go func(i int, item *gobean.PopulateItem) {
+			defer wg.Done()
+			components[i] = registry.checkBean(goCtx, item)
+		}(i, item)
+	}
+	wg.Wait()
+
+	report := &HealthReport{Status: StatusUp, Components: map[string]ComponentHealth{}, CheckedAt: time.Now()}
+	used := map[string]int{}
+
+	for i, item := range items {
+		name := graphNodeName(item)
+
+		if used[name]++; used[name] > 1 {
+			name = fmt.Sprintf("%s#%d", name, used[name])
+		}
+		report.Components[name] = components[i]
+
+		if components[i].Status == StatusDown {
+			report.Status = StatusDown
+		} else if components[i].Status != StatusUp && report.Status == StatusUp {
+			report.Status = StatusDegraded
+		}
+	}
+
+	registry.cached = report
+	return *report
+}
+
+func (registry *HealthRegistry) notReady() string {
+	switch {
+	case registry.closing.Load():
+		return "application context closing"
+	case registry.ctx.state.Load() != stateFrozen:
+		return "application context not wired"
+	default:
+		return ""
+	}
+}
+
+// checkBean calls a HealthIndicator within the timeout, a panic or an unknown status is DOWN.
+func (registry *HealthRegistry) checkBean(goCtx context.Context, item *gobean.PopulateItem) ComponentHealth {
+	start := time.Now()
+
+	checkCtx, cancel := context.WithTimeout(goCtx, time.Duration(registry.timeout.Load()))
+	defer cancel()
+
+	done := make(chan ComponentHealth, 1)
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- ComponentHealth{Status: StatusDown, Error: fmt.Sprintf("panic: %v", r)}
+			}
+		}()
+
+		if bean, err := registry.ctx.readBean(item); err != nil {
+			done <- ComponentHealth{Status: StatusDown, Error: err.Error()}
+		} else if status := bean.(HealthIndicator).Health(checkCtx); status != StatusUp && status != StatusDegraded && status != StatusDown {
+			done <- ComponentHealth{Status: StatusDown, Error: fmt.Sprintf("unknown status '%s'", status)}
+		} else {
+			done <- ComponentHealth{Status: status}
+		}
+	}()
+
+	var health ComponentHealth
+
+	select {
+	case health = <-done:
+	case <-checkCtx.Done():
+		health = ComponentHealth{Status: StatusDown, Error: checkCtx.Err().Error()}
+	}
+
+	health.Duration = time.Since(start).String()
+	return health
+}
+
+// Handler serves the reports as JSON, mount it on a mux with a prefix stripped:
+//
+//	mux.Handle("/health/", http.StripPrefix("/health", applicationContext.Health().Handler()))
+//
+// "/live" is the liveness probe, UP as long as the process responds, "/ready" the readiness probe
+// (503 if DOWN) and "/" the report with every bean.
+func (registry *HealthRegistry) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/live", func(w http.ResponseWriter, r *http.Request) {
+		writeHealth(w, http.StatusOK, HealthReport{Status: StatusUp, CheckedAt: time.Now()})
+	})
+
+	mux.HandleFunc("/ready", func(w http.ResponseWriter, r *http.Request) {
+		report := registry.Check(r.Context())
+		writeHealth(w, healthHTTPStatus(report.Status), HealthReport{Status: report.Status, Reason: report.Reason, CheckedAt: report.CheckedAt})
+	})
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.Trim(r.URL.Path, "/") != "" {
+			http.NotFound(w, r)
+			return
+		}
+
+		report := registry.Check(r.Context())
+		writeHealth(w, healthHTTPStatus(report.Status), report)
+	})
+	return mux
+}
+
+func healthHTTPStatus(status Status) int {
+	if status == StatusDown {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusOK
+}
+
+func writeHealth(w http.ResponseWriter, httpStatus int, report HealthReport) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(httpStatus)
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,195 @@
+package summer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type healthDatabase struct{}
+
+func (db *healthDatabase) Health(goCtx context.Context) Status {
+	return StatusDegraded
+}
+
+type healthProbe struct {
+	Registry *HealthRegistry `inject:"*"`
+}
+
+func TestHealthRegistryIsNotABean(t *testing.T) {
+	ctx := New()
+	probe := &healthProbe{}
+	ctx.Add(probe, &healthDatabase{})
+
+	if err := ctx.PerformAutoWiringContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if probe.Registry != ctx.Health() {
+		t.Fatal("the health registry of the context is not injected")
+	}
+
+	if n := ctx.Each(func(data interface{}) {}); n != 2 {
+		t.Fatalf("Each visited %d beans, want 2", n)
+	}
+
+	if graph := ctx.Graph(); len(graph.Nodes) != 2 || len(graph.Edges) != 0 {
+		t.Fatalf("unexpected graph: %+v", graph)
+	}
+
+	if registry, err := ctx.Get((*HealthRegistry)(nil)); err != nil || registry != ctx.Health() {
+		t.Fatalf("Get of the health registry: %v", err)
+	}
+
+	if report := probe.Registry.Check(context.Background()); report.Status != StatusDegraded || len(report.Components) != 1 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+}
+
+type healthSwitch struct {
+	status atomic.Value
+	calls  atomic.Int32
+}
+
+func newHealthSwitch(status Status) *healthSwitch {
+	indicator := &healthSwitch{}
+	indicator.status.Store(status)
+	return indicator
+}
+
+func (s *healthSwitch) Health(goCtx context.Context) Status {
+	s.calls.Add(1)
+	return s.status.Load().(Status)
+}
+
+type healthSlow struct{}
+
+func (s *healthSlow) Health(goCtx context.Context) Status {
+	<-goCtx.Done()
+	time.Sleep(10 * time.Millisecond) // too late anyway
+	return StatusUp
+}
+
+type healthPanic struct{}
+
+func (p *healthPanic) Health(goCtx context.Context) Status {
+	panic("disk on fire")
+}
+
+func wiredHealth(t *testing.T, beans map[string]interface{}) *HealthRegistry {
+	t.Helper()
+
+	ctx := New()
+
+	for name, bean := range beans {
+		ctx.AddWithName(name, bean)
+	}
+
+	if err := ctx.PerformAutoWiringContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	ctx.Health().SetCacheTTL(0)
+	return ctx.Health()
+}
+
+func TestHealthIndicatorFailures(t *testing.T) {
+	registry := wiredHealth(t, map[string]interface{}{
+		"up":    newHealthSwitch(StatusUp),
+		"slow":  &healthSlow{},
+		"panic": &healthPanic{},
+		"odd":   newHealthSwitch("MAYBE"),
+	})
+	registry.SetTimeout(50 * time.Millisecond)
+
+	start := time.Now()
+	report := registry.Check(context.Background())
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("a slow indicator held the check for %v", elapsed)
+	}
+
+	if report.Status != StatusDown {
+		t.Errorf("composite status: got %s, want %s", report.Status, StatusDown)
+	}
+
+	for name, want := range map[string]ComponentHealth{
+		"up":    {Status: StatusUp},
+		"slow":  {Status: StatusDown, Error: context.DeadlineExceeded.Error()},
+		"panic": {Status: StatusDown, Error: "panic: disk on fire"},
+		"odd":   {Status: StatusDown, Error: "unknown status 'MAYBE'"},
+	} {
+		if got := report.Components[name]; got.Status != want.Status || got.Error != want.Error {
+			t.Errorf("%s: got %+v, want %+v", name, got, want)
+		}
+	}
+}
+
+func TestHealthCacheTTL(t *testing.T) {
+	indicator := newHealthSwitch(StatusUp)
+	registry := wiredHealth(t, map[string]interface{}{"switch": indicator})
+	registry.SetCacheTTL(time.Hour)
+
+	registry.Check(context.Background())
+	indicator.status.Store(StatusDown)
+
+	if report := registry.Check(context.Background()); report.Status != StatusUp || indicator.calls.Load() != 1 {
+		t.Errorf("within the TTL: got %s after %d calls, want the cached %s", report.Status, indicator.calls.Load(), StatusUp)
+	}
+
+	registry.SetCacheTTL(0)
+
+	if report := registry.Check(context.Background()); report.Status != StatusDown || indicator.calls.Load() != 2 {
+		t.Errorf("once expired: got %s after %d calls, want %s", report.Status, indicator.calls.Load(), StatusDown)
+	}
+}
+
+func TestHealthHandler(t *testing.T) {
+	indicator := newHealthSwitch(StatusDegraded)
+	registry := wiredHealth(t, map[string]interface{}{"switch": indicator})
+	handler := registry.Handler()
+
+	for _, test := range []struct {
+		status Status
+		path   string
+		code   int
+	}{
+		{StatusDegraded, "/ready", http.StatusOK},
+		{StatusDegraded, "/", http.StatusOK},
+		{StatusDown, "/ready", http.StatusServiceUnavailable},
+		{StatusDown, "/", http.StatusServiceUnavailable},
+		{StatusDown, "/live", http.StatusOK},
+		{StatusDown, "/other", http.StatusNotFound},
+	} {
+		indicator.status.Store(test.status)
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest("GET", test.path, nil))
+
+		if recorder.Code != test.code {
+			t.Errorf("%s %s: got %d, want %d", test.status, test.path, recorder.Code, test.code)
+		}
+	}
+}
+
+func TestHealthNotReadyUntilWired(t *testing.T) {
+	ctx := New()
+	ctx.Add(newHealthSwitch(StatusUp))
+
+	recorder := httptest.NewRecorder()
+	ctx.Health().Handler().ServeHTTP(recorder, httptest.NewRequest("GET", "/ready", nil))
+
+	var report HealthReport
+
+	if err := json.Unmarshal(recorder.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+
+	if recorder.Code != http.StatusServiceUnavailable || report.Status != StatusDown || !strings.Contains(report.Reason, "not wired") {
+		t.Errorf("got %d %+v", recorder.Code, report)
+	}
+}
@@ -0,0 +1,80 @@
+package summer
+
+import (
+	"container/list"
+	"github.com/linuzilla/summer/gobean"
+	"reflect"
+	"sync"
+)
+
+// typeIndex finds beans by type without walking through every bean.
+// Beans of a concrete type are indexed when added, beans implementing an interface are
+// cached the first time the interface is looked up.
+type typeIndex struct {
+	members    map[*gobean.PopulateItem]bool
+	byType     map[reflect.Type][]*gobean.PopulateItem // bean type -> beans, in registration order
+	interfaces *sync.Map                               // interface type -> beans, in registration order
+}
+
+func newTypeIndex(items *list.List) *typeIndex {
+	index := &typeIndex{
+		members:    map[*gobean.PopulateItem]bool{},
+		byType:     map[reflect.Type][]*gobean.PopulateItem{},
+		interfaces: &sync.Map{},
+	}
+
+	for e := items.Front(); e != nil; e = e.Next() {
+		item := e.Value.(*gobean.PopulateItem)
+		index.members[item] = true
+		index.byType[item.BeanType] = append(index.byType[item.BeanType], item)
+	}
+	return index
+}
+
+// add indexes a new bean, interfaces it implements have to be looked up again.
+func (index *typeIndex) add(item *gobean.PopulateItem) {
+	index.members[item] = true
+	index.byType[item.BeanType] = append(index.byType[item.BeanType], item)
+	index.interfaces = &sync.Map{}
+}
+
+// contains tells whether the bean has been indexed.
+func (index *typeIndex) contains(item *gobean.PopulateItem) bool {
+	return index.members[item]
+}
+
+// itemsOf returns every bean, wired or not, matching the model type (see assignable).
+// The slice is shared, it must not be modified.
+func (ctx *contextManagerImpl) itemsOf(modelType reflect.Type) []*gobean.PopulateItem {
+	switch modelType.Kind() {
+	case reflect.Struct:
+		return ctx.index.byType[reflect.PointerTo(modelType)]
+
+	case reflect.Interface:
+		if cached, found := ctx.index.interfaces.Load(modelType); found {
+			return cached.([]*gobean.PopulateItem)
+		}
+
+		var matchedItems []*gobean.PopulateItem
+
+		implements := map[reflect.Type]bool{}
+
+		for e := ctx.items.Front(); e != nil; e = e.Next() {
+			item := e.Value.(*gobean.PopulateItem)
+			matched, checked := implements[item.BeanType]
+
+			if !checked {
+				matched = item.BeanType.Implements(modelType)
+				implements[item.BeanType] = matched
+			}
+
+			if matched {
+				matchedItems = append(matchedItems, item)
+			}
+		}
+
+		cached, _ := ctx.index.interfaces.LoadOrStore(modelType, matchedItems)
+		return cached.([]*gobean.PopulateItem)
+	}
+	return nil
+}
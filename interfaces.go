@@ -1,24 +1,83 @@
 // Try to provide "dependency injection" mechanism on the Go world.
 package summer
 
+import (
+	"context"
+	"log/slog"
+)
+
 // kind of like "@PostConstruct" in Spring framework
 type HavePostConstruct interface {
 	PostSummerConstruct()
 }
 
+// same as HavePostConstruct, but a failure aborts autowiring
+type HavePostConstructE interface {
+	PostSummerConstructE() error
+}
+
+// same as HavePostConstructE, with the context given to PerformAutoWiringContext
+type HavePostConstructCtx interface {
+	PostSummerConstructCtx(goCtx context.Context) error
+}
+
+// kind of like "@Order" in Spring framework, beans injected into a slice are sorted by it (lower first),
+// beans not implementing it are treated as 0, ties keep registration order.
+type HaveOrder interface {
+	SummerOrder() int
+}
+
+// kind of like "@PreDestroy" in Spring framework, called by Close.
+// A bean implementing io.Closer (but not HavePreDestroy) will be closed as well.
+type HavePreDestroy interface {
+	PreSummerDestroy() error
+}
+
 type ApplicationContextManager interface {
 	// Add "beans" to, the "bean" should be a "pointer" or "interface", however, "pointer to interface" is not recommended.
 	Add(beans ...interface{}) ApplicationContextManager
 
 	// To avoid more the one candidate "beans", use name to distinguish between them.
-	AddWithName(beanName string, bean interface{}) ApplicationContextManager
+	AddWithName(beanName string, bean interface{}, options ...Option) ApplicationContextManager
+
+	// Add a "bean" with options, such as Profile("prod") or OnMissingBean[Mailer]()
+	AddWithOptions(bean interface{}, options ...Option) ApplicationContextManager
+
+	// Add "beans" in a scope other than singleton, ScopePrototype for example.
+	// The "bean" given is a template, every instance is a copy of it wired on its own.
+	AddWithScope(scopeName string, beans ...interface{}) ApplicationContextManager
+
+	// Register a provider function, such as func(a *Rabbit, c sub.ICat) (*Dog, error),
+	// its arguments are resolved by type (or by name given in Qualifiers option) and the returned value becomes a bean.
+	Provide(constructor interface{}, options ...Option) ApplicationContextManager
+
+	// Same as Provide, with a name associated with the returned bean.
+	ProvideWithName(beanName string, constructor interface{}, options ...Option) ApplicationContextManager
+
+	// Register a custom scope, to be used by InScope option or AddWithScope.
+	RegisterScope(scopeName string, scope Scope)
+
+	// Beans registered with Profile option are only kept if one of their profiles is active.
+	// Without calling it, profiles are taken from "summer.profiles.active" property.
+	// Like every setter of the context, it panics (ErrWiringInProgress or ErrFrozen) once autowiring has started.
+	SetActiveProfiles(profiles ...string)
+
+	ActiveProfiles() []string
+
+	// Beans left out by their conditions, with the reasons, available once autowiring starts.
+	SkippedBeans() []SkippedBean
 
-	// To perform dependency injection.
+	// To perform dependency injection. Lookups are safe from any goroutine while autowiring,
+	// adding beans is rejected (panics with ErrWiringInProgress) until it is done. A successful autowiring
+	// freezes the context: beans can not be added anymore (ErrFrozen) and lookups take no lock.
 	Autowiring(callback func(err error)) chan error
 
 	// a newer version of "Autowiring" function
 	PerformAutoWiring(onError func(err error)) ApplicationContextManager
 
+	// perform dependency injection, goCtx is given to PostSummerConstructCtx and stops autowiring once done
+	PerformAutoWiringContext(goCtx context.Context) error
+
 	// retrieve bean based on argument variable type, argument should be a "pinter to interface" or "pointer to structure".
 	Get(intf interface{}) (interface{}, error)
 
@@ -33,9 +92,15 @@ type ApplicationContextManager interface {
 
 	// plugin can be loaded and put them in the context and perform dependency injection as well.
 	// every plugin can only inject a variable using plugin's filename as variable name
-	// LoadPlugins will try the find the exported variable using "FileNameToExportedVariable" function
+	// LoadPlugins will try the find the exported variable using "FileNameToExportedVariable" function,
+	// unless the plugin exports "func SummerRegister(reg summer.Registry) error" to register its beans itself.
+	// A plugin with a manifest (see PluginManifest) is loaded after the plugins it requires, and skipped
+	// (with a *PluginError given to callback) if they are missing or it requires another host API version.
 	LoadPlugins(path string, callback func(beanName string, file string, module interface{}, err error)) error
 
+	// plugin files LoadPlugins has tried to load in load order, with their manifests, bean names and errors
+	Plugins() []PluginInfo
+
 	// If the default "exported variable name" converting function not suite for you,
 	// provide a relevant one.
 	SetExportedVariableNameFunc(function func(string) string)
@@ -47,11 +112,63 @@ type ApplicationContextManager interface {
 
 	// By default, The setter name of a variable is follow Java's setter idea with the first letter 'S' capitalized.
 	// However, there is no standard "setter" function in Go world
-	SetSetterNameFunc(function func(string)string)
+	SetSetterNameFunc(function func(string) string)
 
 	// The field wanted to be inject require a tag, the default tag is 'inject'.
 	// Change tag name if other name is desired
 	SetTagName(tagName string)
 
+	// Fields tagged with `value:"${server.port:8080}"` are filled with properties from the environment
+	// before dependency injection, the default tag is 'value'.
+	SetValueTagName(tagName string)
+
+	// The environment properties are resolved from, by default it has OS environment variables only.
+	Environment() *Environment
+
+	// Replace the environment, before autowiring.
+	SetEnvironment(env *Environment)
+
+	// start every Lifecycle bean by phase, a bean after the beans it depends on, beans not depending on each other
+	// are started in parallel. If one of them fails, beans already started are stopped and the error returned.
+	Start(goCtx context.Context) error
+
+	// stop every running Lifecycle bean, in reverse order of Start
+	Stop(goCtx context.Context) error
+
+	// publish ContextClosing, stop Lifecycle beans, wait for async events to be delivered, then destroy every wired bean
+	// in reverse dependency order, stop waiting once goCtx is done.
+	// errors are joined together, calling Close more than once is harmless.
+	Close(goCtx context.Context) error
+
+	// how beans are wired (or would be, before autowiring), can be written in DOT, Mermaid or JSON
+	Graph() *Graph
+
+	// create a child context, beans of ctx can be injected into its beans or looked up from it (unless
+	// shadowed by a bean of the same name in the child), but not the other way around.
+	NewChild() ApplicationContextManager
+
+	// the context NewChild was called on, nil for a root context
+	Parent() ApplicationContextManager
+
+	// diagnostics are written to a slog text handler on stderr by default, only warnings and errors show up
+	// unless Debug(true) is called. SetLogger routes them elsewhere, every message carries the bean type,
+	// name, field, tag and the "file:line" the bean was added as attributes.
+	SetLogger(logger *slog.Logger)
+	Logger() *slog.Logger
+
+	// turn on debug messages of the default logger
 	Debug(on bool)
+
+	// the built-in event publisher, the one injected into fields of type EventPublisher
+	Events() EventPublisher
+
+	// number of workers and size of the queue delivering events given to PublishAsync, 4 and 64 by default,
+	// it should be done before the first async event.
+	SetEventWorkers(workers int, queueSize int)
+
+	// errors of listeners of async events are logged as warnings, unless a handler is given
+	SetEventErrorHandler(handler func(event interface{}, err error))
+
+	// the built-in health registry, checking every wired HealthIndicator bean, it serves probes by Handler()
+	Health() *HealthRegistry
 }
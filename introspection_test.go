@@ -0,0 +1,67 @@
+package summer
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+func envProperties(t *testing.T, ctx ApplicationContextManager, options ...IntrospectionOption) map[string]string {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	IntrospectionHandler(ctx, options...).ServeHTTP(recorder, httptest.NewRequest("GET", "/env", nil))
+
+	var properties []PropertyInfo
+
+	if err := json.Unmarshal(recorder.Body.Bytes(), &properties); err != nil {
+		t.Fatal(err)
+	}
+
+	values := map[string]string{}
+
+	for _, property := range properties {
+		values[property.Key] = property.Value
+	}
+	return values
+}
+
+func TestEnvMasksSecrets(t *testing.T) {
+	t.Setenv("INTROSPECTION_DATABASE_URL", "postgres://app:hunter2@db:5432/orders")
+	t.Setenv("INTROSPECTION_PORT", "9090")
+
+	ctx := New()
+	ctx.Environment().AddLast(NewMapSource("application", map[string]string{
+		"server.port":        "8080",
+		"db.password":        "hunter2",
+		"db.url":             "${INTROSPECTION_DATABASE_URL}",
+		"mirrors":            "https://alice:pw@a.example.com/x,https://token123@b.example.com/y",
+		"INTROSPECTION_PORT": "80",
+	}))
+
+	values := envProperties(t, ctx)
+
+	for key, want := range map[string]string{
+		"server.port":        "8080",
+		"db.password":        maskedValue,
+		"db.url":             "postgres://app:" + maskedValue + "@db:5432/orders",
+		"mirrors":            "https://alice:" + maskedValue + "@a.example.com/x,https://" + maskedValue + "@b.example.com/y",
+		"INTROSPECTION_PORT": "9090", // overridden by the OS environment
+	} {
+		if values[key] != want {
+			t.Errorf("%s: got %q, want %q", key, values[key], want)
+		}
+	}
+
+	if _, found := values["INTROSPECTION_DATABASE_URL"]; found {
+		t.Error("OS environment variables are listed without ExposeOSEnv")
+	}
+
+	if values := envProperties(t, ctx, ExposeOSEnv()); values["INTROSPECTION_DATABASE_URL"] != "postgres://app:"+maskedValue+"@db:5432/orders" {
+		t.Errorf("INTROSPECTION_DATABASE_URL: got %q", values["INTROSPECTION_DATABASE_URL"])
+	}
+
+	if values := envProperties(t, ctx, WithSecretPatterns("port")); values["server.port"] != maskedValue || values["db.password"] != "hunter2" {
+		t.Errorf("secret patterns not replaced: %v", values)
+	}
+}
@@ -0,0 +1,126 @@
+package summer
+
+import (
+	"github.com/linuzilla/summer/gobean"
+	"log/slog"
+	"reflect"
+)
+
+// Lazy defers PostSummerConstruct (and the provider function, for a bean given to Provide) until the bean
+// is first looked up, resolved by a Provider or injected into a bean being wired. Only singletons can be lazy.
+func Lazy() Option {
+	return func(reg *registration) {
+		reg.lazy = true
+	}
+}
+
+// initLazy creates (if provided) and post constructs a lazy bean, only the first call does the work.
+func (ctx *contextManagerImpl) initLazy(item *gobean.PopulateItem) error {
+	return item.InitLazy(func() error {
+		return ctx.unlocked(func() error {
+			if item.IsProvider() {
+				ctx.logger.Debug("provide", beanAttrs(item, slog.Bool("lazy", true))...)
+
+				if err := item.Construct(); err != nil {
+					return &BeanError{Name: item.Name, Type: item.BeanType.String(), Source: item.Source, Err: err}
+				}
+			}
+
+			if err := ctx.postConstruct(item); err != nil {
+				return &PostConstructError{Source: item.Source, Err: err}
+			}
+			return nil
+		})
+	})
+}
+
+// Provider is a field type resolving a bean when Get is called rather than when autowiring,
+// tagged the same way as a field of type T:
+//
+//	Index summer.Provider[*SearchIndex] `inject:"*"`
+//	Cache summer.Provider[Cache]        `inject:"redis,optional"`
+//
+// A Provider does not make its owner depend on the bean, so it also breaks a circular dependency.
+type Provider[T any] struct {
+	resolve func() (interface{}, error)
+}
+
+// Get resolves the bean, a lazy bean is initialized by the first call.
+// An optional Provider without any bean returns the zero value of T and no error.
+func (provider Provider[T]) Get() (T, error) {
+	var zero T
+
+	if provider.resolve == nil {
+		return zero, &LookupError{Type: typeOf[T](), Err: ErrNotWired}
+	} else if bean, err := provider.resolve(); err != nil || bean == nil {
+		return zero, err
+	} else if typed, ok := bean.(T); ok {
+		return typed, nil
+	} else {
+		return zero, &LookupError{Type: typeOf[T](), Matched: 1, Err: ErrWrongType}
+	}
+}
+
+// MustGet is like Get but panics if the bean can not be resolved.
+func (provider Provider[T]) MustGet() T {
+	if bean, err := provider.Get(); err != nil {
+		panic(err)
+	} else {
+		return bean
+	}
+}
+
+func (provider Provider[T]) providedType() reflect.Type {
+	return typeOf[T]()
+}
+
+func (provider Provider[T]) bind(resolve func() (interface{}, error)) interface{} {
+	return Provider[T]{resolve: resolve}
+}
+
+// providerField is implemented by every Provider[T]
+type providerField interface {
+	providedType() reflect.Type
+	bind(resolve func() (interface{}, error)) interface{}
+}
+
+var providerFieldType = reflect.TypeOf((*providerField)(nil)).Elem()
+
+func isProviderField(elemField *gobean.ElementField) bool {
+	return elemField.StructField.Type.Implements(providerFieldType)
+}
+
+// fieldModelType is the type a field is matched with, T for a Provider[T].
+func fieldModelType(elemField *gobean.ElementField) reflect.Type {
+	if isProviderField(elemField) {
+		return modelTypeOf(reflect.Zero(elemField.StructField.Type).Interface().(providerField).providedType())
+	}
+	return elemField.ModelType()
+}
+
+// injectProvider sets a Provider field, it is wired at once since nothing is resolved until Get is called.
+func (ctx *contextManagerImpl) injectProvider(item *gobean.PopulateItem, elemField *gobean.ElementField) error {
+	provider := reflect.Zero(elemField.StructField.Type).Interface().(providerField).bind(func() (interface{}, error) {
+		if builtin := ctx.builtinOf(fieldModelType(elemField)); builtin != nil && elemField.Tag.ByType {
+			return builtin, nil
+		}
+
+		unlock := ctx.readLock()
+		matchedItem, err := ctx.resolveDependency(elemField)
+		unlock()
+
+		if err != nil {
+			return nil, err
+		} else if matchedItem == nil { // optional
+			return nil, nil
+		}
+		return ctx.readBean(matchedItem)
+	})
+
+	if item.Scope == "" {
+		if err := ctx.setValueToField(item, elemField, provider); err != nil {
+			return err
+		}
+	}
+	return ctx.markFieldWired(item, elemField, nil, "provider")
+}
@@ -0,0 +1,111 @@
+package summer
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type lazyIndex struct {
+	constructed int
+}
+
+func (index *lazyIndex) PostSummerConstruct() {
+	index.constructed++
+}
+
+type lazyModel struct{}
+
+type lazyUser struct {
+	Index *lazyIndex `inject:"*"`
+}
+
+func TestLazyIsConstructedOnFirstAccess(t *testing.T) {
+	ctx := New()
+	index := &lazyIndex{}
+	provided := 0
+
+	ctx.AddWithOptions(index, Lazy())
+	ctx.Provide(func() *lazyModel {
+		provided++
+		return &lazyModel{}
+	}, Lazy())
+
+	if err := ctx.PerformAutoWiringContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if index.constructed != 0 || provided != 0 {
+		t.Fatalf("before any access: constructed %d, provided %d", index.constructed, provided)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := Get[*lazyIndex](ctx); err != nil {
+			t.Fatal(err)
+		} else if _, err := Get[*lazyModel](ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if index.constructed != 1 || provided != 1 {
+		t.Errorf("after two lookups: constructed %d, provided %d, want 1 and 1", index.constructed, provided)
+	}
+}
+
+func TestLazyInjectedWhileWiring(t *testing.T) {
+	ctx := New()
+	index := &lazyIndex{}
+
+	ctx.AddWithOptions(index, Lazy())
+	ctx.Add(&lazyUser{})
+
+	if err := ctx.PerformAutoWiringContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if index.constructed != 1 {
+		t.Errorf("injected into a bean being wired: constructed %d, want 1", index.constructed)
+	}
+}
+
+type lazyParent struct {
+	Child *lazyChild `inject:"*"`
+}
+
+type lazyChild struct {
+	Parent  Provider[*lazyParent] `inject:"*"`
+	Missing Provider[*lazyModel]  `inject:"*,optional"`
+}
+
+type lazyStrictChild struct {
+	Parent *lazyStrictParent `inject:"*"`
+}
+
+type lazyStrictParent struct {
+	Child *lazyStrictChild `inject:"*"`
+}
+
+func TestProviderBreaksCycle(t *testing.T) {
+	ctx := New()
+	parent, child := &lazyParent{}, &lazyChild{}
+	ctx.Add(parent, child)
+
+	if err := ctx.PerformAutoWiringContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := child.Parent.Get(); err != nil || got != parent {
+		t.Errorf("got %v, %v, want the parent", got, err)
+	}
+
+	if got, err := child.Missing.Get(); err != nil || got != nil {
+		t.Errorf("an optional provider without bean: got %v, %v", got, err)
+	}
+
+	strict := New()
+	strict.Add(&lazyStrictParent{}, &lazyStrictChild{})
+
+	if err := strict.PerformAutoWiringContext(context.Background()); !errors.Is(err, ErrCycle) {
+		t.Errorf("without a provider: got %v, want %v", err, ErrCycle)
+	}
+}
@@ -0,0 +1,218 @@
+package summer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/linuzilla/summer/gobean"
+	"log/slog"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// kind of like "SmartLifecycle" in Spring framework, for beans running in the background
+// (servers, consumers, schedulers ...), started by Start once every bean is wired and stopped by Stop or Close.
+type Lifecycle interface {
+	Start(goCtx context.Context) error
+	Stop(goCtx context.Context) error
+	IsRunning() bool
+}
+
+// Lifecycle beans are started by phase (lower first) and stopped the other way around,
+// beans not implementing it are in phase 0.
+type HavePhase interface {
+	SummerPhase() int
+}
+
+var lifecycleType = reflect.TypeOf((*Lifecycle)(nil)).Elem()
+
+type lifecycleBean struct {
+	item      *gobean.PopulateItem
+	lifecycle Lifecycle
+	phase     int
+	depth     int // number of lifecycle beans it depends on, one after another
+}
+
+// lifecycleStages groups the lifecycle beans in the order they are started, beans of a stage
+// do not depend on each other and are started in parallel. Lazy beans never used are only included to be started.
+func (ctx *contextManagerImpl) lifecycleStages(starting bool) ([][]*lifecycleBean, error) {
+	unlock := ctx.readLock()
+	wiredOrder := ctx.wiredOrder
+	edges := map[*gobean.PopulateItem][]dependencyEdge{}
+
+	for _, item := range wiredOrder {
+		edges[item] = ctx.dependencyEdges(item)
+	}
+	unlock()
+
+	var beans []*lifecycleBean
+
+	depth := map[*gobean.PopulateItem]int{}
+	isLifecycle := map[*gobean.PopulateItem]bool{}
+
+	// dependencies first, beans of the parent context are left alone
+	for _, item := range wiredOrder {
+		for _, edge := range edges[item] {
+			if isLifecycle[edge.target] {
+				depth[item] = max(depth[item], depth[edge.target]+1)
+			} else {
+				depth[item] = max(depth[item], depth[edge.target])
+			}
+		}
+
+		if item.Scope != "" {
+			continue
+		} else if item.Lazy && !item.LazyInitialized() && (!starting || !item.BeanType.Implements(lifecycleType)) {
+			continue
+		}
+
+		bean, err := ctx.readBean(item) // a lazy lifecycle bean is initialized to be started
+
+		if err != nil {
+			return nil, err
+		} else if lifecycle, ok := bean.(Lifecycle); ok {
+			phase := 0
+
+			if phased, ok := bean.(HavePhase); ok {
+				phase = phased.SummerPhase()
+			}
+
+			isLifecycle[item] = true
+			beans = append(beans, &lifecycleBean{item: item, lifecycle: lifecycle, phase: phase, depth: depth[item]})
+		}
+	}
+
+	sort.SliceStable(beans, func(i, j int) bool {
+		if beans[i].phase != beans[j].phase {
+			return beans[i].phase < beans[j].phase
+		}
+		return beans[i].depth < beans[j].depth
+	})
+
+	var stages [][]*lifecycleBean
+
+	for i, bean := range beans {
+		if i == 0 || bean.phase != beans[i-1].phase || bean.depth != beans[i-1].depth {
+			stages = append(stages, nil)
+		}
+		stages[len(stages)-1] = append(stages[len(stages)-1], bean)
+	}
+	return stages, nil
+}
+
+// runStage calls action on every bean of a stage in parallel.
+func runStage(stage []*lifecycleBean, action func(bean *lifecycleBean) error) error {
+	var wg sync.WaitGroup
+
+	errs := make([]error, len(stage))
+
+	for i, bean := range stage {
+		wg.Add(1)
+
+		go func(i int, bean *lifecycleBean) {
+			defer wg.Done()
+			errs[i] = action(bean)
+		}(i, bean)
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// Start starts every lifecycle bean not running yet, a bean after the beans it depends on.
+// If one of them fails, those already started are stopped again.
+func (ctx *contextManagerImpl) Start(goCtx context.Context) error {
+	if ctx.state.Load() != stateFrozen {
+		return fmt.Errorf("can not start: %w", ErrNotWired)
+	}
+
+	ctx.lifecycleMu.Lock()
+	defer ctx.lifecycleMu.Unlock()
+
+	stages, err := ctx.lifecycleStages(true)
+
+	if err != nil {
+		return err
+	}
+
+	// only beans started here are rolled back
+	var started [][]*lifecycleBean
+
+	for _, stage := range stages {
+		var mu sync.Mutex
+		var succeeded []*lifecycleBean
+
+		err := runStage(stage, func(bean *lifecycleBean) error {
+			if bean.lifecycle.IsRunning() {
+				return nil
+			}
+
+			ctx.logger.Debug("start", beanAttrs(bean.item, slog.Int("phase", bean.phase))...)
+
+			if err := bean.lifecycle.Start(goCtx); err != nil {
+				return fmt.Errorf("%s: start: %w", bean.item.Source, err)
+			}
+
+			mu.Lock()
+			succeeded = append(succeeded, bean)
+			mu.Unlock()
+			return nil
+		})
+
+		started = append(started, succeeded)
+
+		if err == nil {
+			err = goCtx.Err()
+		}
+
+		if err != nil {
+			ctx.logger.Warn("start failed, rolling back", slog.Any("error", err))
+
+			return errors.Join(err, ctx.stopStages(context.WithoutCancel(goCtx), started))
+		}
+	}
+	return nil
+}
+
+// Stop stops every running lifecycle bean, a bean before the beans it depends on.
+func (ctx *contextManagerImpl) Stop(goCtx context.Context) error {
+	if ctx.state.Load() != stateFrozen {
+		return nil
+	}
+
+	ctx.lifecycleMu.Lock()
+	defer ctx.lifecycleMu.Unlock()
+
+	// a lazy bean never used is not running, it is left alone
+	if stages, err := ctx.lifecycleStages(false); err != nil {
+		return err
+	} else {
+		return ctx.stopStages(goCtx, stages)
+	}
+}
+
+// stopStages stops the beans in reverse order, every stage is stopped even if some beans fail.
+func (ctx *contextManagerImpl) stopStages(goCtx context.Context, stages [][]*lifecycleBean) error {
+	var errs []error
+
+	for i := len(stages) - 1; i >= 0; i-- {
+		err := runStage(stages[i], func(bean *lifecycleBean) error {
+			if !bean.lifecycle.IsRunning() {
+				return nil
+			}
+
+			ctx.logger.Debug("stop", beanAttrs(bean.item, slog.Int("phase", bean.phase))...)
+
+			if err := bean.lifecycle.Stop(goCtx); err != nil {
+				return fmt.Errorf("%s: stop: %w", bean.item.Source, err)
+			}
+			return nil
+		})
+
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,249 @@
+package summer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+type lifecycleServer struct {
+	constructed int
+	started     int
+	stopped     int
+	running     bool
+}
+
+func (s *lifecycleServer) PostSummerConstruct() {
+	s.constructed++
+}
+
+func (s *lifecycleServer) Start(goCtx context.Context) error {
+	s.started++
+	s.running = true
+	return nil
+}
+
+func (s *lifecycleServer) Stop(goCtx context.Context) error {
+	s.stopped++
+	s.running = false
+	return nil
+}
+
+func (s *lifecycleServer) IsRunning() bool {
+	return s.running
+}
+
+func TestCloseLeavesLazyLifecycleBeansAlone(t *testing.T) {
+	ctx := New()
+	server := &lifecycleServer{}
+	ctx.AddWithOptions(server, Lazy())
+
+	if err := ctx.PerformAutoWiringContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ctx.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	} else if err := ctx.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if server.constructed != 0 || server.started != 0 || server.stopped != 0 {
+		t.Fatalf("a lazy bean never started was touched: %+v", *server)
+	}
+}
+
+func TestStopStopsLazyLifecycleBeansStarted(t *testing.T) {
+	ctx := New()
+	server := &lifecycleServer{}
+	ctx.AddWithOptions(server, Lazy())
+
+	if err := ctx.PerformAutoWiringContext(context.Background()); err != nil {
+		t.Fatal(err)
+	} else if err := ctx.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	} else if err := ctx.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if server.constructed != 1 || server.started != 1 || server.stopped != 1 {
+		t.Fatalf("unexpected lifecycle: %+v", *server)
+	}
+}
+
+// lifecycleStep records its Start and Stop into a log shared by every bean of a test
+type lifecycleStep struct {
+	name     string
+	log      *lifecycleLog
+	phase    int
+	fail     bool
+	together *sync.WaitGroup // if set, Start waits until every bean of the group is starting
+	running  bool
+}
+
+type lifecycleLog struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (log *lifecycleLog) add(event string) {
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	log.events = append(log.events, event)
+}
+
+func (s *lifecycleStep) Start(goCtx context.Context) error {
+	s.log.add("start " + s.name)
+
+	if s.together != nil {
+		s.together.Done()
+
+		waited := make(chan struct{})
+
+		go func() {
+			s.together.Wait()
+			close(waited)
+		}()
+
+		select {
+		case <-waited:
+		case <-time.After(5 * time.Second):
+			return fmt.Errorf("%s: not started in parallel", s.name)
+		}
+	}
+
+	if s.fail {
+		return errBoom
+	}
+	s.running = true
+	return nil
+}
+
+func (s *lifecycleStep) Stop(goCtx context.Context) error {
+	s.log.add("stop " + s.name)
+	s.running = false
+	return nil
+}
+
+func (s *lifecycleStep) IsRunning() bool {
+	return s.running
+}
+
+func (s *lifecycleStep) SummerPhase() int {
+	return s.phase
+}
+
+type lifecycleStore struct {
+	lifecycleStep
+}
+
+type lifecycleCache struct {
+	lifecycleStep
+}
+
+type lifecycleAPI struct {
+	lifecycleStep
+	Store *lifecycleStore `inject:"*"`
+	Cache *lifecycleCache `inject:"*"`
+}
+
+type lifecycleGateway struct {
+	lifecycleStep
+	API *lifecycleAPI `inject:"*"`
+}
+
+type lifecycleMetrics struct {
+	lifecycleStep
+}
+
+type lifecycleScheduler struct {
+	lifecycleStep
+}
+
+// sameEvents compares events, the events of a stage started in parallel may come in any order
+func sameEvents(got []string, want ...[]string) bool {
+	for _, stage := range want {
+		if len(got) < len(stage) {
+			return false
+		}
+
+		events, expected := slices.Clone(got[:len(stage)]), slices.Clone(stage)
+		slices.Sort(events)
+		slices.Sort(expected)
+
+		if !slices.Equal(events, expected) {
+			return false
+		}
+		got = got[len(stage):]
+	}
+	return len(got) == 0
+}
+
+func TestLifecycleOrder(t *testing.T) {
+	log := &lifecycleLog{}
+	together := &sync.WaitGroup{}
+	together.Add(2)
+
+	ctx := New()
+	ctx.Add(
+		&lifecycleGateway{lifecycleStep: lifecycleStep{name: "gateway", log: log}},
+		&lifecycleAPI{lifecycleStep: lifecycleStep{name: "api", log: log}},
+		&lifecycleStore{lifecycleStep: lifecycleStep{name: "store", log: log, together: together}},
+		&lifecycleCache{lifecycleStep: lifecycleStep{name: "cache", log: log, together: together}},
+		&lifecycleScheduler{lifecycleStep: lifecycleStep{name: "scheduler", log: log, phase: 1}},
+		&lifecycleMetrics{lifecycleStep: lifecycleStep{name: "metrics", log: log, phase: -1}},
+	)
+
+	if err := ctx.PerformAutoWiringContext(context.Background()); err != nil {
+		t.Fatal(err)
+	} else if err := ctx.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// phase first, then dependencies first, store and cache do not depend on each other
+	if !sameEvents(log.events, []string{"start metrics"}, []string{"start store", "start cache"},
+		[]string{"start api"}, []string{"start gateway"}, []string{"start scheduler"}) {
+		t.Errorf("start: got %v", log.events)
+	}
+
+	log.events = nil
+
+	if err := ctx.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !sameEvents(log.events, []string{"stop scheduler"}, []string{"stop gateway"}, []string{"stop api"},
+		[]string{"stop store", "stop cache"}, []string{"stop metrics"}) {
+		t.Errorf("stop: got %v", log.events)
+	}
+}
+
+func TestLifecycleRollback(t *testing.T) {
+	log := &lifecycleLog{}
+
+	ctx := New()
+	ctx.Add(
+		&lifecycleStore{lifecycleStep: lifecycleStep{name: "store", log: log}},
+		&lifecycleCache{lifecycleStep: lifecycleStep{name: "cache", log: log, phase: -1}},
+		&lifecycleAPI{lifecycleStep: lifecycleStep{name: "api", log: log}},
+		&lifecycleGateway{lifecycleStep: lifecycleStep{name: "gateway", log: log, fail: true}},
+		&lifecycleScheduler{lifecycleStep: lifecycleStep{name: "scheduler", log: log, phase: 1}},
+	)
+
+	if err := ctx.PerformAutoWiringContext(context.Background()); err != nil {
+		t.Fatal(err)
+	} else if err := ctx.Start(context.Background()); !errors.Is(err, errBoom) {
+		t.Fatalf("got %v, want %v", err, errBoom)
+	}
+
+	// the beans already started are stopped the other way around, the scheduler is never started
+	want := []string{"start cache", "start store", "start api", "start gateway", "stop api", "stop store", "stop cache"}
+
+	if !slices.Equal(log.events, want) {
+		t.Errorf("got %v, want %v", log.events, want)
+	}
+}
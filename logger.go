@@ -0,0 +1,51 @@
+package summer
+
+import (
+	"github.com/linuzilla/summer/gobean"
+	"log/slog"
+	"os"
+)
+
+// newDefaultLogger writes warnings and errors to stderr, debug messages as well once Debug(true) is called.
+func newDefaultLogger(level *slog.LevelVar) *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+}
+
+// SetLogger routes every diagnostic to logger, Debug has no effect on it, the level is up to its handler.
+func (ctx *contextManagerImpl) SetLogger(logger *slog.Logger) {
+	ctx.configure("logger", func() {
+		ctx.logger = logger
+	})
+}
+
+func (ctx *contextManagerImpl) Logger() *slog.Logger {
+	return ctx.logger
+}
+
+// Debug turns on debug messages of the default logger, Debug(false) brings it back to warnings and errors.
+func (ctx *contextManagerImpl) Debug(on bool) {
+	if on {
+		ctx.logLevel.Set(slog.LevelDebug)
+	} else {
+		ctx.logLevel.Set(slog.LevelWarn)
+	}
+}
+
+// beanAttrs describes the item for a log message, followed by args.
+func beanAttrs(item *gobean.PopulateItem, args ...any) []any {
+	attrs := []any{slog.String("bean", item.BeanType.String())}
+
+	if item.Name != "" {
+		attrs = append(attrs, slog.String("name", item.Name))
+	}
+	return append(append(attrs, slog.String("source", item.Location)), args...)
+}
+
+// fieldAttrs describes the field of the item for a log message, followed by args.
+func fieldAttrs(item *gobean.PopulateItem, elemField *gobean.ElementField, args ...any) []any {
+	return beanAttrs(item, append([]any{
+		slog.String("field", elemField.StructField.Name),
+		slog.String("type", elemField.StructField.Type.String()),
+		slog.String("tag", elemField.Tag.Raw),
+	}, args...)...)
+}
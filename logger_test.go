@@ -0,0 +1,38 @@
+package summer
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+)
+
+func TestDefaultLogLevel(t *testing.T) {
+	ctx := New()
+	logger := ctx.Logger()
+
+	for i, test := range []struct {
+		debug   bool
+		enabled []slog.Level
+		muted   []slog.Level
+	}{
+		{false, []slog.Level{slog.LevelWarn, slog.LevelError}, []slog.Level{slog.LevelDebug, slog.LevelInfo}},
+		{true, []slog.Level{slog.LevelDebug, slog.LevelInfo, slog.LevelWarn}, nil},
+		{false, []slog.Level{slog.LevelWarn}, []slog.Level{slog.LevelDebug, slog.LevelInfo}},
+	} {
+		if i > 0 { // the first one is the default
+			ctx.Debug(test.debug)
+		}
+
+		for _, level := range test.enabled {
+			if !logger.Enabled(context.Background(), level) {
+				t.Errorf("Debug(%v): %s should show up", test.debug, level)
+			}
+		}
+
+		for _, level := range test.muted {
+			if logger.Enabled(context.Background(), level) {
+				t.Errorf("Debug(%v): %s should not show up", test.debug, level)
+			}
+		}
+	}
+}
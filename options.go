@@ -0,0 +1,39 @@
+package summer
+
+// Option customizes a bean while it is registered by AddWithName, AddWithOptions, Provide or ProvideWithName.
+type Option func(reg *registration)
+
+type registration struct {
+	scope      string
+	qualifiers []string
+	conditions []condition
+	lazy       bool
+}
+
+func newRegistration(options []Option) *registration {
+	reg := &registration{}
+
+	for _, option := range options {
+		option(reg)
+	}
+	return reg
+}
+
+// InScope puts the bean into a scope other than singleton, ScopePrototype for example.
+func InScope(scopeName string) Option {
+	return func(reg *registration) {
+		if scopeName == ScopeSingleton {
+			reg.scope = ""
+		} else {
+			reg.scope = scopeName
+		}
+	}
+}
+
+// Qualifiers gives bean names, in order, for arguments of a provider function,
+// an empty string (or "*") means matching by type.
+func Qualifiers(beanNames ...string) Option {
+	return func(reg *registration) {
+		reg.qualifiers = beanNames
+	}
+}
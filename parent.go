@@ -0,0 +1,143 @@
+package summer
+
+import (
+	"container/list"
+	"github.com/linuzilla/summer/gobean"
+	"maps"
+	"reflect"
+)
+
+// NewChild creates a context seeing the beans of ctx, but not the other way around.
+// A child starts with the settings of its parent (tags, logger, scopes, profiles), its environment has no source
+// of its own at first and falls back to the environment of the parent, sources added to it are not seen by the parent.
+// its beans may shadow beans of the parent by name, and closing it leaves the parent alone.
+// The parent should be wired before the child.
+func (ctx *contextManagerImpl) NewChild() ApplicationContextManager {
+	defer ctx.readLock()()
+
+	return (&contextManagerImpl{
+		parent:                   ctx,
+		items:                    list.New(),
+		itemsMap:                 map[string]*gobean.PopulateItem{},
+		index:                    newTypeIndex(list.New()),
+		logger:                   ctx.logger,
+		logLevel:                 ctx.logLevel,
+		injectionTag:             ctx.injectionTag,
+		valueTag:                 ctx.valueTag,
+		environment:              &Environment{parent: ctx.environment},
+		pluginNamePrefix:         ctx.pluginNamePrefix,
+		setterNameFunc:           ctx.setterNameFunc,
+		exportedVariableNameFunc: ctx.exportedVariableNameFunc,
+		scopes:                   maps.Clone(ctx.scopes),
+		conditions:               map[*gobean.PopulateItem][]condition{},
+		activeProfiles:           ctx.activeProfiles,
+		wiringCtx:                ctx.wiringCtx,
+	}).addEventBus().addHealthRegistry()
+}
+
+// Parent returns nil for a root context.
+func (ctx *contextManagerImpl) Parent() ApplicationContextManager {
+	if ctx.parent == nil {
+		return nil
+	}
+	return ctx.parent
+}
+
+// ownerOf returns the context an item was added to, ctx itself or one of its ancestors.
+// The caller holds the lock of ctx (if needed), those of the ancestors are taken here.
+func (ctx *contextManagerImpl) ownerOf(item *gobean.PopulateItem) *contextManagerImpl {
+	if ctx.index.contains(item) {
+		return ctx
+	}
+
+	for parent := ctx.parent; parent != nil; parent = parent.parent {
+		unlock := parent.readLock()
+		found := parent.index.contains(item)
+		unlock()
+
+		if found {
+			return parent
+		}
+	}
+	return ctx
+}
+
+// parentWiredEntryByType is findWiredEntryByType for a type no bean of ctx matches.
+func (ctx *contextManagerImpl) parentWiredEntryByType(modelType reflect.Type) (*gobean.PopulateItem, int) {
+	if ctx.parent == nil {
+		return nil, 0
+	}
+
+	defer ctx.parent.readLock()()
+	return ctx.parent.findWiredEntryByType(modelType)
+}
+
+// parentResolveByType is resolveByType for a type no bean of ctx matches.
+func (ctx *contextManagerImpl) parentResolveByType(modelType reflect.Type) (*gobean.PopulateItem, error) {
+	if ctx.parent == nil {
+		return nil, &LookupError{Type: modelType, Err: ErrNoSuchBean}
+	}
+
+	defer ctx.parent.readLock()()
+	return ctx.parent.resolveByType(modelType)
+}
+
+// parentBeanByName is getBeanByName for a name ctx has no bean of.
+func (ctx *contextManagerImpl) parentBeanByName(beanName string) (*gobean.PopulateItem, bool, error) {
+	if ctx.parent == nil {
+		return nil, false, &LookupError{Name: beanName, Err: ErrNoSuchBean}
+	}
+
+	defer ctx.parent.readLock()()
+	return ctx.parent.getBeanByName(beanName)
+}
+
+// parentItemsOf lists beans of the ancestors matching the type, nearest first.
+func (ctx *contextManagerImpl) parentItemsOf(modelType reflect.Type) []*gobean.PopulateItem {
+	var items []*gobean.PopulateItem
+
+	for parent := ctx.parent; parent != nil; parent = parent.parent {
+		unlock := parent.readLock()
+		items = append(items, parent.itemsOf(modelType)...)
+		unlock()
+	}
+	return items
+}
+
+// shadowed tells whether a bean of an ancestor is hidden by a bean of the same name in ctx or a nearer ancestor.
+func (ctx *contextManagerImpl) shadowed(item *gobean.PopulateItem) bool {
+	if item.Name == "" {
+		return false
+	} else if _, found := ctx.itemsMap[item.Name]; found {
+		return true
+	}
+
+	for parent := ctx.parent; parent != nil; parent = parent.parent {
+		unlock := parent.readLock()
+		foundItem, found := parent.itemsMap[item.Name]
+		unlock()
+
+		if found {
+			return foundItem != item
+		}
+	}
+	return false
+}
+
+// itemByName finds a bean, wired or not, by name in ctx or its ancestors.
+func (ctx *contextManagerImpl) itemByName(beanName string) *gobean.PopulateItem {
+	if item, found := ctx.itemsMap[beanName]; found {
+		return item
+	}
+
+	for parent := ctx.parent; parent != nil; parent = parent.parent {
+		unlock := parent.readLock()
+		item, found := parent.itemsMap[beanName]
+		unlock()
+
+		if found {
+			return item
+		}
+	}
+	return nil
+}
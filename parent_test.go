@@ -0,0 +1,43 @@
+package summer
+
+import (
+	"testing"
+)
+
+func TestChildEnvironmentFallsBackToParent(t *testing.T) {
+	root := New()
+	root.SetEnvironment(NewEnvironment(NewMapSource("root", map[string]string{"db.url": "root-db", "name": "root"})))
+
+	child := root.NewChild()
+	sibling := root.NewChild()
+
+	child.Environment().AddFirst(NewMapSource("child", map[string]string{"name": "child"}))
+	child.Environment().AddLast(NewMapSource("plugin", map[string]string{"plugin.only": "yes"}))
+
+	for _, test := range []struct {
+		env   *Environment
+		key   string
+		value string
+		found bool
+	}{
+		{child.Environment(), "name", "child", true},
+		{child.Environment(), "db.url", "root-db", true},
+		{child.Environment(), "plugin.only", "yes", true},
+		{root.Environment(), "name", "root", true},
+		{root.Environment(), "plugin.only", "", false},
+		{sibling.Environment(), "name", "root", true},
+		{sibling.Environment(), "plugin.only", "", false},
+	} {
+		if value, found := test.env.Lookup(test.key); value != test.value || found != test.found {
+			t.Errorf("%s: got %q %v, want %q %v", test.key, value, found, test.value, test.found)
+		}
+	}
+
+	if sources := child.Environment().Sources(); len(sources) != 3 || sources[2].Name() != "root" {
+		t.Errorf("unexpected sources of the child: %v", sources)
+	}
+
+	if sources := root.Environment().Sources(); len(sources) != 1 {
+		t.Errorf("the parent has %d sources, want 1", len(sources))
+	}
+}
@@ -0,0 +1,325 @@
+package summer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// APIVersion is the version of the host API plugins are built against, see PluginManifest.HostAPI.
+const APIVersion = "1.0.0"
+
+// PluginManifest describes a plugin, read from a sidecar file named after it ("report.json",
+// "report.yaml" or "report.yml" for "report.so"). It is read without opening the plugin, since opening it
+// runs its init functions, which can not be undone: a plugin is only opened once it is its turn to be loaded,
+// and only if its requirements are satisfied. A plugin without a manifest has neither version nor requirement.
+//
+//	name: report
+//	version: 1.2.0
+//	hostApi: ">=1.0.0"
+//	requires: ["storage>=2.0,<3.0", mailer]
+type PluginManifest struct {
+	Name     string   `json:"name"`               // the file name without extension by default
+	Version  string   `json:"version,omitempty"`  // "major.minor.patch"
+	HostAPI  string   `json:"hostApi,omitempty"`  // a constraint on APIVersion, such as ">=1.0.0" or "^1.0"
+	Requires []string `json:"requires,omitempty"` // other plugins, loaded before it: "name" or "name>=1.2.0"
+}
+
+// pluginCandidate is a plugin file found by LoadPlugins, not loaded yet.
+type pluginCandidate struct {
+	moduleName           string
+	exportedVariableName string
+	info                 *PluginInfo
+	requires             []pluginRequirement
+	err                  error // the plugin is skipped
+}
+
+type pluginRequirement struct {
+	name       string
+	constraint string
+}
+
+// pluginCandidate reads the manifest of a plugin file, a plugin without one has no requirements.
+func (ctx *contextManagerImpl) pluginCandidate(path string, moduleName string) *pluginCandidate {
+	exportedVariableName := ctx.exportedVariableNameFunc(moduleName)
+
+	plug := &pluginCandidate{
+		moduleName:           moduleName,
+		exportedVariableName: exportedVariableName,
+		info: &PluginInfo{
+			File:     filepath.Join(path, moduleName),
+			BeanName: ctx.pluginNamePrefix + exportedVariableName,
+			Name:     strings.TrimSuffix(moduleName, filepath.Ext(moduleName)),
+		},
+	}
+
+	manifest, err := readPluginManifest(plug.info.File)
+
+	if err != nil {
+		plug.err = err
+		return plug
+	} else if manifest == nil {
+		return plug
+	}
+
+	if manifest.Name != "" {
+		plug.info.Name = manifest.Name
+	}
+	plug.info.Version = manifest.Version
+	plug.info.Requires = manifest.Requires
+
+	if ok, err := versionSatisfies(APIVersion, manifest.HostAPI); err != nil {
+		plug.err = &PluginError{File: plug.info.File, Name: plug.info.Name, Err: fmt.Errorf("%w: host API '%s': %v", ErrIncompatiblePlugin, manifest.HostAPI, err)}
+	} else if !ok {
+		plug.err = &PluginError{File: plug.info.File, Name: plug.info.Name, Err: fmt.Errorf("%w: requires host API %s, host is %s", ErrIncompatiblePlugin, manifest.HostAPI, APIVersion)}
+	}
+
+	for _, require := range manifest.Requires {
+		if i := strings.IndexAny(require, "<>=^~ "); i >= 0 {
+			plug.requires = append(plug.requires, pluginRequirement{name: strings.TrimSpace(require[:i]), constraint: strings.TrimSpace(require[i:])})
+		} else {
+			plug.requires = append(plug.requires, pluginRequirement{name: strings.TrimSpace(require)})
+		}
+	}
+	return plug
+}
+
+// readPluginManifest returns nil if the plugin has no sidecar manifest, the plugin itself is not opened.
+func readPluginManifest(file string) (*PluginManifest, error) {
+	base := strings.TrimSuffix(file, filepath.Ext(file))
+
+	for _, ext := range []string{".json", ".yaml", ".yml"} {
+		path := base + ext
+
+		if data, err := os.ReadFile(path); os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		} else if ext == ".json" {
+			var document map[string]interface{}
+
+			if err := json.Unmarshal(data, &document); err != nil {
+				return nil, fmt.Errorf("%s: %v", path, err)
+			}
+			return manifestOf(document), nil
+		} else if document, err := parseYAML(string(data)); err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		} else {
+			return manifestOf(document), nil
+		}
+	}
+	return nil, nil
+}
+
+// manifestOf reads a manifest document, requires is taken as a list since a constraint may have commas in it.
+func manifestOf(document map[string]interface{}) *PluginManifest {
+	manifest := &PluginManifest{
+		Name:    manifestString(document["name"]),
+		Version: manifestString(document["version"]),
+		HostAPI: manifestString(document["hostApi"]),
+	}
+
+	switch requires := document["requires"].(type) {
+	case []interface{}:
+		for _, require := range requires {
+			if require := strings.TrimSpace(manifestString(require)); require != "" {
+				manifest.Requires = append(manifest.Requires, require)
+			}
+		}
+	case nil:
+	default: // a single plugin
+		if require := strings.TrimSpace(manifestString(requires)); require != "" {
+			manifest.Requires = []string{require}
+		}
+	}
+	return manifest
+}
+
+func manifestString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// orderPlugins puts every plugin after those it requires, keeping the directory order otherwise.
+// Plugins with a requirement not found, of another version, or in a cycle are skipped.
+func orderPlugins(candidates []*pluginCandidate) []*pluginCandidate {
+	byName := map[string]*pluginCandidate{}
+
+	for _, plug := range candidates {
+		if existing, found := byName[plug.info.Name]; found && plug.err == nil {
+			plug.err = &PluginError{File: plug.info.File, Name: plug.info.Name, Err: fmt.Errorf("%w, also in %s", ErrDuplicateName, existing.info.File)}
+		} else if !found {
+			byName[plug.info.Name] = plug
+		}
+	}
+
+	for _, plug := range candidates {
+		for _, require := range plug.requires {
+			if plug.err != nil {
+				break
+			} else if dependency, found := byName[require.name]; !found {
+				plug.err = &PluginError{File: plug.info.File, Name: plug.info.Name, Err: fmt.Errorf("%w: plugin '%s' not found", ErrPluginDependency, require.name)}
+			} else if ok, err := versionSatisfies(dependency.info.Version, require.constraint); err != nil || !ok {
+				plug.err = &PluginError{File: plug.info.File, Name: plug.info.Name, Err: fmt.Errorf("%w: requires plugin '%s' %s, found version '%s'", ErrPluginDependency, require.name, require.constraint, dependency.info.Version)}
+			}
+		}
+	}
+
+	var ordered []*pluginCandidate
+
+	done := map[*pluginCandidate]bool{}
+
+	// a skipped plugin is ready right away, its requirements do not matter
+	for progress := true; progress; {
+		progress = false
+
+		for _, plug := range candidates {
+			if done[plug] {
+				continue
+			}
+
+			ready := true
+
+			for _, require := range plug.requires {
+				if plug.err == nil && !done[byName[require.name]] {
+					ready = false
+				}
+			}
+
+			if ready {
+				ordered = append(ordered, plug)
+				done[plug] = true
+				progress = true
+				break // restart, so the directory order is kept as much as possible
+			}
+		}
+	}
+
+	for _, plug := range candidates {
+		if !done[plug] {
+			plug.err = &PluginError{File: plug.info.File, Name: plug.info.Name, Err: fmt.Errorf("%w: %w between plugins", ErrPluginDependency, ErrCycle)}
+			ordered = append(ordered, plug)
+		}
+	}
+	return ordered
+}
+
+// loadPlugins loads the candidates in order, a plugin requiring one failed to load is skipped as well.
+func (ctx *contextManagerImpl) loadPlugins(candidates []*pluginCandidate, callback func(beanName string, file string, module interface{}, err error)) {
+	loaded := map[string]bool{}
+
+	for _, plug := range orderPlugins(candidates) {
+		err := plug.err
+
+		for _, require := range plug.requires {
+			if err == nil && !loaded[require.name] {
+				err = &PluginError{File: plug.info.File, Name: plug.info.Name, Err: fmt.Errorf("%w: plugin '%s' failed to load", ErrPluginDependency, require.name)}
+			}
+		}
+
+		if err != nil {
+			ctx.pluginDone(plug, nil, err, callback)
+		} else if ctx.loadPlugin(plug, callback) == nil {
+			loaded[plug.info.Name] = true
+		}
+	}
+}
+
+// versionSatisfies checks a version against a constraint, an empty constraint is always satisfied.
+// A constraint is a comma separated list of ">=", ">", "<=", "<", "=", "^" (same major) or "~" (same minor)
+// followed by a version, a version alone is the same as "^".
+func versionSatisfies(version string, constraint string) (bool, error) {
+	if strings.TrimSpace(constraint) == "" {
+		return true, nil
+	}
+
+	actual, err := parseVersion(version)
+
+	if err != nil {
+		return false, err
+	}
+
+	for _, term := range strings.Split(constraint, ",") {
+		term = strings.TrimSpace(term)
+		op := strings.TrimRight(term[:len(term)-len(strings.TrimLeft(term, "<>=^~"))], " ")
+
+		wanted, err := parseVersion(strings.TrimSpace(term[len(op):]))
+
+		if err != nil {
+			return false, err
+		}
+
+		var ok bool
+
+		switch cmp := compareVersions(actual, wanted); op {
+		case ">=":
+			ok = cmp >= 0
+		case ">":
+			ok = cmp > 0
+		case "<=":
+			ok = cmp <= 0
+		case "<":
+			ok = cmp < 0
+		case "=", "==":
+			ok = cmp == 0
+		case "~":
+			ok = cmp >= 0 && actual[0] == wanted[0] && actual[1] == wanted[1]
+		case "^", "":
+			ok = cmp >= 0 && actual[0] == wanted[0] && (wanted[0] != 0 || actual[1] == wanted[1])
+		default:
+			return false, fmt.Errorf("unknown operator '%s'", op)
+		}
+
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// parseVersion parses "major.minor.patch", with an optional "v" prefix, missing parts are 0
+// and a pre-release or build suffix is ignored.
+func parseVersion(version string) ([3]int, error) {
+	var parsed [3]int
+
+	text := strings.TrimPrefix(strings.TrimSpace(version), "v")
+
+	if i := strings.IndexAny(text, "-+"); i >= 0 {
+		text = text[:i]
+	}
+
+	parts := strings.Split(text, ".")
+
+	if text == "" || len(parts) > 3 {
+		return parsed, fmt.Errorf("invalid version '%s'", version)
+	}
+
+	for i, part := range parts {
+		if n, err := strconv.Atoi(part); err != nil || n < 0 {
+			return parsed, fmt.Errorf("invalid version '%s'", version)
+		} else {
+			parsed[i] = n
+		}
+	}
+	return parsed, nil
+}
+
+func compareVersions(a [3]int, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] - b[i]
+		}
+	}
+	return 0
+}
@@ -0,0 +1,117 @@
+package summer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestReadPluginManifest(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"report.json":  `{"name": "report", "version": "1.2.0", "hostApi": "^1.0", "requires": ["storage>=1.0,<2.0", "mailer"]}`,
+		"storage.yaml": "name: storage\nversion: 1.4.0\nrequires: [\"cache>=1.0,<2.0\", mailer]\n",
+		"mailer.yml":   "name: mailer\nrequires:\n  - cache>=1.0,<2.0\n",
+	})
+
+	for file, want := range map[string]PluginManifest{
+		"report.so":  {Name: "report", Version: "1.2.0", HostAPI: "^1.0", Requires: []string{"storage>=1.0,<2.0", "mailer"}},
+		"storage.so": {Name: "storage", Version: "1.4.0", Requires: []string{"cache>=1.0,<2.0", "mailer"}},
+		"mailer.so":  {Name: "mailer", Requires: []string{"cache>=1.0,<2.0"}},
+	} {
+		manifest, err := readPluginManifest(filepath.Join(dir, file))
+
+		if err != nil {
+			t.Fatal(err)
+		} else if manifest == nil || manifest.Name != want.Name || manifest.Version != want.Version || manifest.HostAPI != want.HostAPI || !slices.Equal(manifest.Requires, want.Requires) {
+			t.Errorf("%s: got %+v, want %+v", file, manifest, want)
+		}
+	}
+
+	// the plugin is not opened, it does not even have to exist
+	if manifest, err := readPluginManifest(filepath.Join(dir, "cache.so")); manifest != nil || err != nil {
+		t.Errorf("cache.so: got %+v, %v", manifest, err)
+	}
+}
+
+func TestOrderPlugins(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a.json": `{"name": "a", "requires": ["b>=1.0,<2.0"]}`,
+		"b.json": `{"name": "b", "version": "1.5.0", "requires": ["c"]}`,
+		"c.json": `{"name": "c", "version": "1.0.0"}`,
+		"d.json": `{"name": "d", "requires": ["c>=2.0"]}`,
+		"e.json": `{"name": "e", "hostApi": ">=2.0"}`,
+	})
+
+	ctx := New().(*contextManagerImpl)
+
+	var candidates []*pluginCandidate
+
+	for _, name := range []string{"a.so", "b.so", "c.so", "d.so", "e.so", "f.so"} {
+		candidates = append(candidates, ctx.pluginCandidate(dir, name))
+	}
+
+	var order []string
+
+	for _, plug := range orderPlugins(candidates) {
+		order = append(order, plug.info.Name)
+
+		switch plug.info.Name {
+		case "d":
+			if !errors.Is(plug.err, ErrPluginDependency) {
+				t.Errorf("d: %v", plug.err)
+			}
+		case "e":
+			if !errors.Is(plug.err, ErrIncompatiblePlugin) {
+				t.Errorf("e: %v", plug.err)
+			}
+		default:
+			if plug.err != nil {
+				t.Errorf("%s: %v", plug.info.Name, plug.err)
+			}
+		}
+	}
+
+	if want := []string{"c", "b", "a", "d", "e", "f"}; !slices.Equal(order, want) {
+		t.Errorf("got %v, want %v", order, want)
+	}
+}
+
+func TestVersionSatisfies(t *testing.T) {
+	for _, test := range []struct {
+		version    string
+		constraint string
+		ok         bool
+	}{
+		{"1.2.0", "", true},
+		{"1.2.0", ">=1.0,<2.0", true},
+		{"2.0.0", ">=1.0,<2.0", false},
+		{"1.9.3", "^1.2", true},
+		{"0.3.1", "^0.2", false},
+		{"1.2.9", "~1.2.0", true},
+		{"1.3.0", "~1.2.0", false},
+		{"v1.2.0-rc1", "=1.2.0", true},
+	} {
+		if ok, err := versionSatisfies(test.version, test.constraint); err != nil || ok != test.ok {
+			t.Errorf("%s %s: got %v %v, want %v", test.version, test.constraint, ok, err, test.ok)
+		}
+	}
+
+	if _, err := versionSatisfies("1.x", ">=1.0"); err == nil {
+		t.Error("an invalid version is accepted")
+	}
+}
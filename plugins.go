@@ -0,0 +1,310 @@
+package summer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/linuzilla/summer/gobean"
+	"log/slog"
+	"plugin"
+	"reflect"
+	"slices"
+)
+
+// a plugin exporting a function of this name, func SummerRegister(reg summer.Registry) error,
+// registers its beans through it instead of having its exported variable added as a bean.
+const PluginRegisterFunc = "SummerRegister"
+
+// PluginInfo is a plugin file LoadPlugins has tried to load.
+// Plugins are listed in the order they are loaded, after those they require.
+type PluginInfo struct {
+	File     string   `json:"file"`
+	Name     string   `json:"name"`               // from the manifest, the file name without extension by default
+	Version  string   `json:"version,omitempty"`  // from the manifest
+	Requires []string `json:"requires,omitempty"` // from the manifest
+	BeanName string   `json:"beanName"`           // the name of its exported variable, prefixed
+	Beans    []string `json:"beans,omitempty"`    // beans it has added, by name or type
+	Loaded   bool     `json:"loaded"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// Registry is given to the SummerRegister function of a plugin. Everything registered is only added
+// to the context once SummerRegister returns nil, every bean required is found and no bean name is taken,
+// all at once, so a plugin failing leaves nothing behind.
+type Registry interface {
+	// the bean name the exported variable of the plugin would have, see PluginInfo
+	PluginName() string
+
+	File() string
+
+	// the environment of the context, properties set by SetProperty are not visible before SummerRegister returns
+	Environment() *Environment
+
+	Add(beans ...interface{}) Registry
+	AddWithName(beanName string, bean interface{}, options ...Option) Registry
+	AddWithOptions(bean interface{}, options ...Option) Registry
+	Provide(constructor interface{}, options ...Option) Registry
+	ProvideWithName(beanName string, constructor interface{}, options ...Option) Registry
+
+	// properties of the plugin, the environment looks them up after every other source
+	SetProperty(key string, value string) Registry
+
+	// the plugin fails to load if the context (or its ancestors) has no bean of this name
+	RequireBean(beanName string) Registry
+
+	// the plugin fails to load if the context (or its ancestors) has no bean of this type,
+	// given like Get: a "pointer to interface" or "pointer to structure"
+	RequireType(intf interface{}) Registry
+}
+
+type pluginBean struct {
+	item     *gobean.PopulateItem
+	beanName string
+	reg      *registration
+}
+
+type pluginRegistry struct {
+	ctx        *contextManagerImpl
+	info       *PluginInfo
+	beans      []pluginBean
+	errs       []error // beans which could not be created
+	properties map[string]string
+	beanNames  []string
+	types      []reflect.Type
+}
+
+func (reg *pluginRegistry) PluginName() string {
+	return reg.info.BeanName
+}
+
+func (reg *pluginRegistry) File() string {
+	return reg.info.File
+}
+
+func (reg *pluginRegistry) Environment() *Environment {
+	return reg.ctx.environment
+}
+
+// register keeps a bean created by the plugin, it is added to the context by apply. Beans are created right away,
+// so their source is the code of the plugin calling the registry.
+func (reg *pluginRegistry) register(description string, beanName string, item *gobean.PopulateItem, err error, options []Option) Registry {
+	if err != nil {
+		reg.errs = append(reg.errs, err)
+	} else {
+		reg.info.Beans = append(reg.info.Beans, description)
+		reg.beans = append(reg.beans, pluginBean{item: item, beanName: beanName, reg: newRegistration(options)})
+	}
+	return reg
+}
+
+func (reg *pluginRegistry) Add(beans ...interface{}) Registry {
+	for _, bean := range beans {
+		item, err := gobean.New(bean, 2, reg.ctx.injectionTag, reg.ctx.valueTag)
+		reg.register(fmt.Sprintf("%T", bean), "", item, err, nil)
+	}
+	return reg
+}
+
+func (reg *pluginRegistry) AddWithName(beanName string, bean interface{}, options ...Option) Registry {
+	item, err := gobean.New(bean, 2, reg.ctx.injectionTag, reg.ctx.valueTag)
+	return reg.register(beanName, beanName, item, err, options)
+}
+
+func (reg *pluginRegistry) AddWithOptions(bean interface{}, options ...Option) Registry {
+	item, err := gobean.New(bean, 2, reg.ctx.injectionTag, reg.ctx.valueTag)
+	return reg.register(fmt.Sprintf("%T", bean), "", item, err, options)
+}
+
+func (reg *pluginRegistry) Provide(constructor interface{}, options ...Option) Registry {
+	item, err := gobean.NewProvider(constructor, newRegistration(options).qualifiers, 2)
+	return reg.register(fmt.Sprintf("provider [%T]", constructor), "", item, err, options)
+}
+
+func (reg *pluginRegistry) ProvideWithName(beanName string, constructor interface{}, options ...Option) Registry {
+	item, err := gobean.NewProvider(constructor, newRegistration(options).qualifiers, 2)
+	return reg.register(beanName, beanName, item, err, options)
+}
+
+func (reg *pluginRegistry) SetProperty(key string, value string) Registry {
+	reg.properties[key] = value
+	return reg
+}
+
+func (reg *pluginRegistry) RequireBean(beanName string) Registry {
+	reg.beanNames = append(reg.beanNames, beanName)
+	return reg
+}
+
+func (reg *pluginRegistry) RequireType(intf interface{}) Registry {
+	reg.types = append(reg.types, reflect.TypeOf(intf).Elem())
+	return reg
+}
+
+// missing lists the beans required but not registered, conditions are not taken into account.
+func (reg *pluginRegistry) missing() error {
+	defer reg.ctx.readLock()()
+
+	var errs []error
+
+	for _, beanName := range reg.beanNames {
+		if reg.ctx.itemByName(beanName) == nil {
+			errs = append(errs, &LookupError{Name: beanName, Err: ErrNoSuchBean})
+		}
+	}
+
+	for _, modelType := range reg.types {
+		if len(reg.ctx.itemsOf(modelType)) == 0 && len(reg.ctx.parentItemsOf(modelType)) == 0 {
+			errs = append(errs, &LookupError{Type: modelType, Err: ErrNoSuchBean})
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// conflicts finds a bean name already taken, in the context or by another bean of the plugin, the way register
+// would reject it: unless one of the beans has conditions. The caller holds the lock of the context.
+func (reg *pluginRegistry) conflicts() error {
+	taken := map[string]*gobean.PopulateItem{}
+	conditional := map[*gobean.PopulateItem]bool{}
+
+	for _, bean := range reg.beans {
+		conditional[bean.item] = len(bean.reg.conditions) > 0
+
+		if bean.beanName == "" {
+			continue
+		}
+
+		existing, found := reg.ctx.itemsMap[bean.beanName]
+
+		if found {
+			conditional[existing] = len(reg.ctx.conditions[existing]) > 0
+		} else {
+			existing, found = taken[bean.beanName]
+		}
+
+		if !found {
+			taken[bean.beanName] = bean.item
+		} else if !conditional[existing] && !conditional[bean.item] {
+			return &BeanError{Name: bean.beanName, Type: bean.item.BeanType.String(), Source: bean.item.Source, Err: fmt.Errorf("%w, already registered at %s", ErrDuplicateName, existing.Source)}
+		}
+	}
+	return nil
+}
+
+// apply adds what the plugin has registered, all at once and only if nothing can fail anymore.
+func (reg *pluginRegistry) apply() error {
+	if err := errors.Join(reg.errs...); err != nil {
+		return fmt.Errorf("%s: %w", reg.info.File, err)
+	} else if err := reg.missing(); err != nil {
+		return fmt.Errorf("%s: beans required: %w", reg.info.File, err)
+	}
+
+	reg.ctx.mu.Lock()
+	defer reg.ctx.mu.Unlock()
+
+	if err := reg.ctx.checkRegistering(); err != nil {
+		return fmt.Errorf("%s: can not add beans: %w", reg.info.File, err)
+	} else if err := reg.conflicts(); err != nil {
+		return fmt.Errorf("%s: %w", reg.info.File, err)
+	}
+
+	for _, bean := range reg.beans {
+		if _, err := reg.ctx.register(bean.item, bean.beanName, bean.reg); err != nil {
+			panic(err) // names are checked by conflicts
+		}
+	}
+
+	if len(reg.properties) > 0 {
+		reg.ctx.environment.AddLast(NewMapSource("plugin "+reg.info.File, reg.properties))
+	}
+	return nil
+}
+
+// loadPlugin opens a plugin and registers its beans.
+func (ctx *contextManagerImpl) loadPlugin(plug *pluginCandidate, callback func(beanName string, file string, module interface{}, err error)) error {
+	module, err := ctx.openPlugin(plug.info, plug.exportedVariableName)
+
+	ctx.pluginDone(plug, module, err, callback)
+	return err
+}
+
+// pluginDone records the outcome of a plugin and reports it to the callback.
+func (ctx *contextManagerImpl) pluginDone(plug *pluginCandidate, module interface{}, err error, callback func(beanName string, file string, module interface{}, err error)) {
+	info := plug.info
+
+	if err != nil {
+		info.Error = err.Error()
+		info.Beans = nil
+	} else {
+		info.Loaded = true
+	}
+	ctx.recordPlugin(*info)
+
+	if err == nil {
+		if err := ctx.events.Publish(context.Background(), PluginLoaded{Context: ctx, BeanName: info.BeanName, File: plug.moduleName, Module: module}); err != nil {
+			ctx.logger.Warn("plugin loaded listener failed", slog.String("plugin", info.BeanName), slog.Any("error", err))
+		}
+	}
+
+	if callback != nil {
+		callback(info.BeanName, plug.moduleName, module, err)
+	}
+}
+
+// openPlugin calls SummerRegister if the plugin has it, otherwise its exported variable is added as a bean.
+// A bean failing to be added (a duplicate name for example) is turned into an error.
+func (ctx *contextManagerImpl) openPlugin(info *PluginInfo, exportedVariableName string) (module interface{}, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			if anError, ok := e.(error); ok {
+				err = anError
+			} else {
+				err = fmt.Errorf("%v", e)
+			}
+		}
+	}()
+
+	plug, err := plugin.Open(info.File)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if symbol, err := plug.Lookup(PluginRegisterFunc); err == nil {
+		register, ok := symbol.(func(Registry) error)
+
+		if !ok {
+			return symbol, fmt.Errorf("%s: %s is %T, not func(summer.Registry) error", info.File, PluginRegisterFunc, symbol)
+		}
+
+		reg := &pluginRegistry{ctx: ctx, info: info, properties: map[string]string{}}
+
+		if err := register(reg); err != nil {
+			return symbol, fmt.Errorf("%s: %s: %w", info.File, PluginRegisterFunc, err)
+		}
+		return symbol, reg.apply()
+	}
+
+	module, err = plug.Lookup(exportedVariableName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ctx.AddWithName(info.BeanName, module)
+	info.Beans = []string{info.BeanName}
+	return module, nil
+}
+
+func (ctx *contextManagerImpl) recordPlugin(info PluginInfo) {
+	ctx.pluginsMu.Lock()
+	defer ctx.pluginsMu.Unlock()
+
+	ctx.plugins = append(ctx.plugins, info)
+}
+
+func (ctx *contextManagerImpl) Plugins() []PluginInfo {
+	ctx.pluginsMu.Lock()
+	defer ctx.pluginsMu.Unlock()
+
+	return slices.Clone(ctx.plugins)
+}
@@ -0,0 +1,95 @@
+package summer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type pluginReport struct{}
+
+type pluginMailer struct{}
+
+func newPluginRegistry(ctx ApplicationContextManager) *pluginRegistry {
+	return &pluginRegistry{ctx: ctx.(*contextManagerImpl), info: &PluginInfo{File: "report.so"}, properties: map[string]string{}}
+}
+
+func TestRegistryLeavesNothingBehindOnConflict(t *testing.T) {
+	ctx := New()
+	ctx.AddWithName("mailer", &pluginMailer{})
+
+	reg := newPluginRegistry(ctx)
+	reg.Add(&pluginReport{}).AddWithName("mailer", &pluginMailer{}).SetProperty("report.dir", "/tmp")
+
+	if err := reg.apply(); !errors.Is(err, ErrDuplicateName) {
+		t.Fatalf("expected %v, got %v", ErrDuplicateName, err)
+	}
+
+	if _, found := ctx.Environment().Lookup("report.dir"); found {
+		t.Error("a property of the plugin was added")
+	}
+
+	if err := ctx.PerformAutoWiringContext(context.Background()); err != nil {
+		t.Fatal(err)
+	} else if _, err := ctx.Get((*pluginReport)(nil)); !errors.Is(err, ErrNoSuchBean) {
+		t.Errorf("a bean of the plugin was added: %v", err)
+	}
+}
+
+func TestRegistryRejectsDuplicateNamesWithinThePlugin(t *testing.T) {
+	ctx := New()
+
+	reg := newPluginRegistry(ctx)
+	reg.AddWithName("report", &pluginReport{}).AddWithName("report", &pluginMailer{})
+
+	if err := reg.apply(); !errors.Is(err, ErrDuplicateName) {
+		t.Fatalf("expected %v, got %v", ErrDuplicateName, err)
+	} else if n := ctx.(*contextManagerImpl).items.Len(); n != 0 {
+		t.Fatalf("%d beans added", n)
+	}
+}
+
+func TestRegistryLeavesNothingBehindOnceFrozen(t *testing.T) {
+	ctx := New()
+
+	if err := ctx.PerformAutoWiringContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	reg := newPluginRegistry(ctx)
+	reg.Add(&pluginReport{}).SetProperty("report.dir", "/tmp")
+
+	if err := reg.apply(); !errors.Is(err, ErrFrozen) {
+		t.Fatalf("expected %v, got %v", ErrFrozen, err)
+	} else if _, found := ctx.Environment().Lookup("report.dir"); found {
+		t.Error("a property of the plugin was added")
+	}
+}
+
+func TestRegistryAddsEverything(t *testing.T) {
+	ctx := New()
+
+	reg := newPluginRegistry(ctx)
+	reg.Add(&pluginReport{}).
+		ProvideWithName("mailer", func() *pluginMailer { return &pluginMailer{} }).
+		SetProperty("report.dir", "/tmp")
+
+	if err := reg.apply(); err != nil {
+		t.Fatal(err)
+	} else if err := ctx.PerformAutoWiringContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := ctx.GetByName("mailer"); err != nil {
+		t.Error(err)
+	}
+
+	if items := ctx.(*contextManagerImpl).itemsOf(typeOf[pluginReport]()); len(items) != 1 || !strings.Contains(items[0].Source, "plugins_test.go") {
+		t.Errorf("the source is not the code of the plugin: %v", items)
+	}
+
+	if dir, _ := ctx.Environment().Lookup("report.dir"); dir != "/tmp" {
+		t.Errorf("report.dir: %q", dir)
+	}
+}
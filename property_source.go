@@ -0,0 +1,379 @@
+package summer
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type mapSource struct {
+	name       string
+	properties map[string]string
+	relaxed    bool // "server.port" can be found as "SERVER_PORT"
+}
+
+// NewMapSource creates a property source from a map, handy for defaults and tests.
+func NewMapSource(name string, properties map[string]string) PropertySource {
+	return &mapSource{name: name, properties: properties}
+}
+
+func (source *mapSource) Name() string {
+	return source.name
+}
+
+func (source *mapSource) Lookup(key string) (string, bool) {
+	if value, found := source.properties[key]; found {
+		return value, true
+	} else if source.relaxed {
+		value, found = source.properties[envVariableName(key)]
+		return value, found
+	}
+	return "", false
+}
+
+func (source *mapSource) Keys() []string {
+	keys := make([]string, 0, len(source.properties))
+
+	for key := range source.properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// envVariableName converts "server.port" to "SERVER_PORT"
+func envVariableName(key string) string {
+	return strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+}
+
+type osEnvSource struct{}
+
+// OSEnvSource looks up OS environment variables, "server.port" can be given as SERVER_PORT as well.
+func OSEnvSource() PropertySource {
+	return osEnvSource{}
+}
+
+func (osEnvSource) Name() string {
+	return "os-env"
+}
+
+func (osEnvSource) Lookup(key string) (string, bool) {
+	if value, found := os.LookupEnv(key); found {
+		return value, true
+	}
+	return os.LookupEnv(envVariableName(key))
+}
+
+func (osEnvSource) Keys() []string {
+	var keys []string
+
+	for _, entry := range os.Environ() {
+		if key, _, found := strings.Cut(entry, "="); found && key != "" {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ArgsSource takes "--server.port=8080" style arguments, a bare "--verbose" means "true".
+// Arguments not starting with "--" are ignored.
+func ArgsSource(args []string) PropertySource {
+	properties := map[string]string{}
+
+	for _, arg := range args {
+		if arg == "--" {
+			break
+		} else if strings.HasPrefix(arg, "--") {
+			if key, value, found := strings.Cut(arg[2:], "="); found {
+				properties[key] = value
+			} else {
+				properties[key] = "true"
+			}
+		}
+	}
+	return &mapSource{name: "command-line", properties: properties}
+}
+
+type flagSource struct {
+	flagSet *flag.FlagSet
+}
+
+// FlagSource looks up flags explicitly set on the command line, flagSet should be parsed already.
+func FlagSource(flagSet *flag.FlagSet) PropertySource {
+	return &flagSource{flagSet: flagSet}
+}
+
+func (source *flagSource) Name() string {
+	return "flags:" + source.flagSet.Name()
+}
+
+func (source *flagSource) Lookup(key string) (value string, found bool) {
+	source.flagSet.Visit(func(f *flag.Flag) {
+		if f.Name == key {
+			value, found = f.Value.String(), true
+		}
+	})
+	return value, found
+}
+
+func (source *flagSource) Keys() []string {
+	var keys []string
+
+	source.flagSet.Visit(func(f *flag.Flag) {
+		keys = append(keys, f.Name)
+	})
+	return keys
+}
+
+// PropertyFile loads a property source by file extension: ".env", ".json", ".yaml" or ".yml".
+func PropertyFile(path string) (PropertySource, error) {
+	switch ext := strings.ToLower(filepath.Ext(path)); {
+	case ext == ".env" || filepath.Base(path) == ".env":
+		return DotEnvSource(path)
+	case ext == ".json":
+		return JSONSource(path)
+	case ext == ".yaml" || ext == ".yml":
+		return YAMLSource(path)
+	default:
+		return nil, fmt.Errorf("%s: unknown type of property file", path)
+	}
+}
+
+// DotEnvSource loads KEY=VALUE lines, "#" starts a comment, "export " prefix and quotes are allowed.
+func DotEnvSource(path string) (PropertySource, error) {
+	file, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	properties := map[string]string{}
+	scanner := bufio.NewScanner(file)
+
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, found := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+
+		if !found {
+			return nil, fmt.Errorf("%s:%d: missing '='", path, lineNo)
+		}
+		properties[strings.TrimSpace(key)] = unquote(strings.TrimSpace(value))
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return &mapSource{name: "dotenv:" + path, properties: properties, relaxed: true}, nil
+}
+
+// JSONSource loads a JSON object, nested objects are flattened as "server.port",
+// arrays become comma separated values.
+func JSONSource(path string) (PropertySource, error) {
+	var document map[string]interface{}
+
+	if data, err := os.ReadFile(path); err != nil {
+		return nil, err
+	} else if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	properties := map[string]string{}
+	flattenProperties("", document, properties)
+	return &mapSource{name: "json:" + path, properties: properties}, nil
+}
+
+// YAMLSource loads a YAML file, only a subset of YAML is understood:
+// nested mappings by indentation, scalars, "- item" sequences and flow sequences "[a, b]".
+func YAMLSource(path string) (PropertySource, error) {
+	if data, err := os.ReadFile(path); err != nil {
+		return nil, err
+	} else if document, err := parseYAML(string(data)); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	} else {
+		properties := map[string]string{}
+		flattenProperties("", document, properties)
+		return &mapSource{name: "yaml:" + path, properties: properties}, nil
+	}
+}
+
+func flattenProperties(prefix string, value interface{}, properties map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			flattenProperties(key, child, properties)
+		}
+
+	case []interface{}:
+		items := make([]string, len(v))
+
+		for i, child := range v {
+			items[i] = fmt.Sprint(child)
+		}
+		properties[prefix] = strings.Join(items, ",")
+
+	case nil:
+		properties[prefix] = ""
+
+	case float64:
+		properties[prefix] = strconv.FormatFloat(v, 'f', -1, 64)
+
+	default:
+		properties[prefix] = fmt.Sprint(v)
+	}
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		if value[0] == '"' {
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				return unquoted
+			}
+		}
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+type yamlLine struct {
+	indent int
+	text   string
+	lineNo int
+}
+
+func parseYAML(data string) (map[string]interface{}, error) {
+	var lines []yamlLine
+
+	for i, line := range strings.Split(data, "\n") {
+		if trimmed := strings.TrimSpace(stripYAMLComment(line)); trimmed != "" && trimmed != "---" {
+			lines = append(lines, yamlLine{indent: len(line) - len(strings.TrimLeft(line, " ")), text: trimmed, lineNo: i + 1})
+		}
+	}
+
+	document, rest, err := parseYAMLMapping(lines, 0)
+
+	if err == nil && len(rest) > 0 {
+		err = fmt.Errorf("line %d: unexpected indentation", rest[0].lineNo)
+	}
+	return document, err
+}
+
+func parseYAMLMapping(lines []yamlLine, indent int) (map[string]interface{}, []yamlLine, error) {
+	mapping := map[string]interface{}{}
+
+	for len(lines) > 0 && lines[0].indent == indent {
+		line := lines[0]
+		key, value, found := strings.Cut(line.text, ":")
+
+		if !found || strings.HasPrefix(line.text, "- ") {
+			return nil, nil, fmt.Errorf("line %d: 'key: value' expected", line.lineNo)
+		}
+
+		key = unquote(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		lines = lines[1:]
+
+		switch {
+		case value != "":
+			mapping[key] = parseYAMLScalar(value)
+
+		case len(lines) > 0 && lines[0].indent > indent && strings.HasPrefix(lines[0].text, "-"):
+			var items []interface{}
+			itemIndent := lines[0].indent
+
+			for len(lines) > 0 && lines[0].indent == itemIndent && strings.HasPrefix(lines[0].text, "-") {
+				items = append(items, parseYAMLScalar(strings.TrimSpace(strings.TrimPrefix(lines[0].text, "-"))))
+				lines = lines[1:]
+			}
+			mapping[key] = items
+
+		case len(lines) > 0 && lines[0].indent > indent:
+			if child, rest, err := parseYAMLMapping(lines, lines[0].indent); err != nil {
+				return nil, nil, err
+			} else {
+				mapping[key] = child
+				lines = rest
+			}
+
+		default:
+			mapping[key] = nil
+		}
+	}
+
+	if len(lines) > 0 && lines[0].indent > indent {
+		return nil, nil, fmt.Errorf("line %d: unexpected indentation", lines[0].lineNo)
+	}
+	return mapping, lines, nil
+}
+
+func parseYAMLScalar(value string) interface{} {
+	if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+		var items []interface{}
+
+		for _, item := range splitYAMLFlow(value[1 : len(value)-1]) {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, unquote(item))
+			}
+		}
+		return items
+	} else if value == "~" || value == "null" {
+		return nil
+	}
+	return unquote(value)
+}
+
+// splitYAMLFlow splits the items of a flow sequence, commas within quotes are kept.
+func splitYAMLFlow(value string) []string {
+	var items []string
+
+	inQuote := byte(0)
+	start := 0
+
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case inQuote != 0:
+			if c == inQuote {
+				inQuote = 0
+			}
+		case c == '"' || c == '\'':
+			inQuote = c
+		case c == ',':
+			items = append(items, value[start:i])
+			start = i + 1
+		}
+	}
+	return append(items, value[start:])
+}
+
+func stripYAMLComment(line string) string {
+	inQuote := byte(0)
+
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case inQuote != 0:
+			if c == inQuote {
+				inQuote = 0
+			}
+		case c == '"' || c == '\'':
+			inQuote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
@@ -0,0 +1,204 @@
+package summer
+
+import (
+	"errors"
+	"flag"
+	"maps"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		yaml string
+		want map[string]string
+	}{
+		{"scalars", "port: 8080\nname: 'summer'\nempty:\nnothing: ~\n", map[string]string{
+			"port": "8080", "name": "summer", "empty": "", "nothing": "",
+		}},
+		{"nesting", "server:\n  port: 8080\n  tls:\n    enabled: true\nname: x\n", map[string]string{
+			"server.port": "8080", "server.tls.enabled": "true", "name": "x",
+		}},
+		{"sequences", "hosts:\n  - a\n  - 'b'\nports: [80, 443]\nquoted: [\"x,y\", z]\n", map[string]string{
+			"hosts": "a,b", "ports": "80,443", "quoted": "x,y,z",
+		}},
+		{"comments", "# heading\n---\nurl: http://h/#anchor # trailing\nsharp: \"a # b\"\nword: a#b\n", map[string]string{
+			"url": "http://h/#anchor", "sharp": "a # b", "word": "a#b",
+		}},
+	} {
+		document, err := parseYAML(test.yaml)
+
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+
+		properties := map[string]string{}
+		flattenProperties("", document, properties)
+
+		if !maps.Equal(properties, test.want) {
+			t.Errorf("%s: got %v, want %v", test.name, properties, test.want)
+		}
+	}
+}
+
+func TestParseMalformedYAML(t *testing.T) {
+	for _, test := range []struct {
+		yaml string
+		want string
+	}{
+		{"server:\n  port: 80\n    host: x\n", "line 3: unexpected indentation"},
+		{"  port: 80\nname: x\n", "line 1: unexpected indentation"},
+		{"server\n", "line 1: 'key: value' expected"},
+		{"hosts:\n  - a\n  port: 80\n", "line 3: unexpected indentation"},
+		{"- a\n", "line 1: 'key: value' expected"},
+	} {
+		if _, err := parseYAML(test.yaml); err == nil || err.Error() != test.want {
+			t.Errorf("%q: got %v, want %s", test.yaml, err, test.want)
+		}
+	}
+}
+
+func TestPropertyFiles(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		".env":     "# database\nexport DB_URL=\"postgres://h/db\"\nDB_USER='scott'\nDB_NAME = summer\n",
+		"app.json": `{"server": {"port": 8080, "ratio": 0.5, "hosts": ["a", "b"]}, "debug": true, "none": null}`,
+		"app.yml":  "server:\n  port: 8080\n",
+	})
+
+	for _, test := range []struct {
+		file string
+		want map[string]string
+	}{
+		{".env", map[string]string{"db.url": "postgres://h/db", "DB_USER": "scott", "db.name": "summer"}},
+		{"app.json", map[string]string{
+			"server.port": "8080", "server.ratio": "0.5", "server.hosts": "a,b", "debug": "true", "none": "",
+		}},
+		{"app.yml", map[string]string{"server.port": "8080"}},
+	} {
+		source, err := PropertyFile(filepath.Join(dir, test.file))
+
+		if err != nil {
+			t.Errorf("%s: %v", test.file, err)
+			continue
+		}
+
+		for key, want := range test.want {
+			if value, found := source.Lookup(key); !found || value != want {
+				t.Errorf("%s: %s = %q (%v), want %q", test.file, key, value, found, want)
+			}
+		}
+	}
+}
+
+func TestMalformedPropertyFiles(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"bad.env":  "A=1\nB\n",
+		"bad.json": `{"a": `,
+		"bad.toml": "a = 1\n",
+	})
+
+	for file, want := range map[string]string{
+		"bad.env":  "bad.env:2: missing '='",
+		"bad.json": "bad.json: unexpected end of JSON input",
+		"bad.toml": "bad.toml: unknown type of property file",
+	} {
+		if _, err := PropertyFile(filepath.Join(dir, file)); err == nil || !strings.HasSuffix(err.Error(), want) {
+			t.Errorf("%s: got %v, want %s", file, err, want)
+		}
+	}
+}
+
+func TestFlagSource(t *testing.T) {
+	flagSet := flag.NewFlagSet("app", flag.ContinueOnError)
+	flagSet.Int("port", 80, "")
+	flagSet.String("host", "localhost", "")
+
+	if err := flagSet.Parse([]string{"-port", "8080"}); err != nil {
+		t.Fatal(err)
+	}
+
+	source := FlagSource(flagSet)
+
+	if value, found := source.Lookup("port"); !found || value != "8080" {
+		t.Errorf("port = %q (%v), want 8080", value, found)
+	}
+
+	if value, found := source.Lookup("host"); found {
+		t.Errorf("a flag not set should not be found, got %q", value)
+	}
+
+	if keys := source.Keys(); len(keys) != 1 || keys[0] != "port" {
+		t.Errorf("keys: got %v, want [port]", keys)
+	}
+}
+
+func TestSourcePrecedence(t *testing.T) {
+	env := NewEnvironment(
+		ArgsSource([]string{"--port=9090", "--verbose", "ignored", "--", "--after=1"}),
+		NewMapSource("defaults", map[string]string{"port": "80", "host": "localhost", "verbose": "false"}),
+	)
+
+	for key, want := range map[string]string{"port": "9090", "host": "localhost", "verbose": "true"} {
+		if value, _ := env.Lookup(key); value != want {
+			t.Errorf("%s = %q, want %q", key, value, want)
+		}
+	}
+
+	if _, found := env.Lookup("after"); found {
+		t.Error("arguments after '--' should be ignored")
+	}
+
+	env.AddFirst(NewMapSource("overrides", map[string]string{"port": "443"}))
+	env.AddLast(NewMapSource("fallbacks", map[string]string{"port": "1", "timeout": "5s"}))
+
+	for key, want := range map[string]string{"port": "443", "timeout": "5s"} {
+		if value, _ := env.Lookup(key); value != want {
+			t.Errorf("%s = %q, want %q", key, value, want)
+		}
+	}
+
+	if keys := env.Keys(); strings.Join(keys, " ") != "host port timeout verbose" {
+		t.Errorf("keys: got %v", keys)
+	}
+}
+
+func TestResolvePlaceholders(t *testing.T) {
+	env := NewEnvironment(NewMapSource("m", map[string]string{
+		"host": "db", "port": "5432", "b": "from-b", "url": "${host}:${port}", "self": "${self}",
+	}))
+
+	for _, test := range []struct {
+		text string
+		want string
+	}{
+		{"plain", "plain"},
+		{"${host}", "db"},
+		{"postgres://${url}/summer", "postgres://db:5432/summer"},
+		{"${missing:fallback}", "fallback"},
+		{"${missing:}", ""},
+		{"${host:fallback}", "db"},
+		{"${a:${b:x}}", "from-b"},
+		{"${a:${c:x}}", "x"},
+		{"${a:${c:${port}}}", "5432"},
+		{"${missing:http://h:80}", "http://h:80"},
+	} {
+		if resolved, err := env.Resolve(test.text); err != nil {
+			t.Errorf("%q: %v", test.text, err)
+		} else if resolved != test.want {
+			t.Errorf("%q: got %q, want %q", test.text, resolved, test.want)
+		}
+	}
+
+	for _, text := range []string{"${missing}", "${a:${missing}}", "${host", "${self}"} {
+		if resolved, err := env.Resolve(text); err == nil {
+			t.Errorf("%q: got %q, want an error", text, resolved)
+		}
+	}
+
+	if _, err := env.Resolve("${a:${missing}}"); !errors.Is(err, ErrNoSuchProperty) || !strings.Contains(err.Error(), "'missing'") {
+		t.Errorf("got %v, want %v naming 'missing'", err, ErrNoSuchProperty)
+	}
+}
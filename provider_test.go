@@ -0,0 +1,96 @@
+package summer
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type providerCat interface {
+	Meow() string
+}
+
+type providerKitty struct {
+	name string
+}
+
+func (k *providerKitty) Meow() string {
+	return k.name
+}
+
+type providerRabbit struct{}
+
+type providerDog struct {
+	rabbit *providerRabbit
+	cat    providerCat
+}
+
+type providerKennel struct {
+	Dog *providerDog `inject:"*"`
+}
+
+func TestProviderArguments(t *testing.T) {
+	ctx := New()
+	kennel := &providerKennel{}
+
+	ctx.Add(kennel, &providerRabbit{})
+	ctx.AddWithName("kitty", &providerKitty{name: "kitty"})
+	ctx.AddWithName("tom", &providerKitty{name: "tom"})
+
+	// by type for the first argument, by name for the second one, two cats would be ambiguous
+	ctx.Provide(func(r *providerRabbit, c providerCat) (*providerDog, error) {
+		return &providerDog{rabbit: r, cat: c}, nil
+	}, Qualifiers("", "tom"))
+
+	if err := ctx.PerformAutoWiringContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if dog := kennel.Dog; dog == nil || dog.rabbit == nil || dog.cat.Meow() != "tom" {
+		t.Fatalf("unexpected dog: %+v", dog)
+	}
+}
+
+func TestProviderWithoutError(t *testing.T) {
+	ctx := New()
+	ctx.ProvideWithName("kitty", func() providerCat { return &providerKitty{name: "kitty"} })
+
+	if err := ctx.PerformAutoWiringContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if cat, err := Get[providerCat](ctx); err != nil || cat.Meow() != "kitty" {
+		t.Fatalf("got %v, %v", cat, err)
+	}
+}
+
+func TestProviderFailing(t *testing.T) {
+	for name, provider := range map[string]interface{}{
+		"error": func() (*providerDog, error) { return &providerDog{}, errBoom },
+		"nil":   func() *providerDog { return nil },
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := New()
+			kennel := &providerKennel{}
+			ctx.Add(kennel)
+			ctx.Provide(provider)
+
+			if err := ctx.PerformAutoWiringContext(context.Background()); err == nil {
+				t.Fatal("autowiring succeeded")
+			} else if name == "error" && !errors.Is(err, errBoom) {
+				t.Fatalf("got %v, want %v", err, errBoom)
+			} else if kennel.Dog != nil {
+				t.Fatal("a bean of a failing provider is injected")
+			}
+		})
+	}
+}
+
+func TestProviderMissingArgument(t *testing.T) {
+	ctx := New()
+	ctx.Provide(func(c providerCat) *providerDog { return &providerDog{cat: c} }, Qualifiers("kitty"))
+
+	if err := ctx.PerformAutoWiringContext(context.Background()); !errors.Is(err, ErrNoSuchBean) {
+		t.Fatalf("got %v, want %v", err, ErrNoSuchBean)
+	}
+}
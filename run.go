@@ -0,0 +1,299 @@
+package summer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"syscall"
+	"time"
+)
+
+const DefaultShutdownTimeout = 30 * time.Second
+
+// kind of like "CommandLineRunner" in Spring Boot, called by Run once the context is wired and started,
+// with the command line arguments. Runners are called one after another, ordered by SummerOrder.
+type CommandLineRunner interface {
+	RunCommandLine(goCtx context.Context, args []string) error
+}
+
+// kind of like "ApplicationRunner" in Spring Boot, same as CommandLineRunner with the context instead.
+type ApplicationRunner interface {
+	RunApplication(goCtx context.Context, ctx ApplicationContextManager) error
+}
+
+var commandLineRunnerType = reflect.TypeOf((*CommandLineRunner)(nil)).Elem()
+var applicationRunnerType = reflect.TypeOf((*ApplicationRunner)(nil)).Elem()
+
+// RunOption customizes Run.
+type RunOption func(cfg *runConfig)
+
+type runConfig struct {
+	goCtx           context.Context
+	modules         []func(ctx ApplicationContextManager) error
+	args            []string
+	signals         []os.Signal
+	shutdownTimeout time.Duration
+	keepRunning     bool
+}
+
+// WithContext gives the context of the whole run, Run shuts down once it is done. context.Background() by default.
+func WithContext(goCtx context.Context) RunOption {
+	return func(cfg *runConfig) {
+		cfg.goCtx = goCtx
+	}
+}
+
+// WithBeans adds beans to the context, like Add.
+func WithBeans(beans ...interface{}) RunOption {
+	return WithModule(func(ctx ApplicationContextManager) error {
+		ctx.Add(beans...)
+		return nil
+	})
+}
+
+// WithNamedBean adds a bean to the context, like AddWithName.
+func WithNamedBean(beanName string, bean interface{}, options ...Option) RunOption {
+	return WithModule(func(ctx ApplicationContextManager) error {
+		ctx.AddWithName(beanName, bean, options...)
+		return nil
+	})
+}
+
+// WithModule registers beans (or sets up the context, its environment for example) before autowiring,
+// modules are called in the order given.
+func WithModule(module func(ctx ApplicationContextManager) error) RunOption {
+	return func(cfg *runConfig) {
+		cfg.modules = append(cfg.modules, module)
+	}
+}
+
+// WithArgs replaces the arguments given to CommandLineRunner, os.Args[1:] by default.
+func WithArgs(args []string) RunOption {
+	return func(cfg *runConfig) {
+		cfg.args = args
+	}
+}
+
+// WithSignals replaces the signals starting a graceful shutdown, SIGINT and SIGTERM by default.
+func WithSignals(signals ...os.Signal) RunOption {
+	return func(cfg *runConfig) {
+		cfg.signals = signals
+	}
+}
+
+// WithShutdownTimeout limits the time runners still running and Close are given once shutting down,
+// DefaultShutdownTimeout by default.
+func WithShutdownTimeout(timeout time.Duration) RunOption {
+	return func(cfg *runConfig) {
+		cfg.shutdownTimeout = timeout
+	}
+}
+
+// KeepRunning waits for a signal even after every runner has completed,
+// without runners Run always waits for a signal.
+func KeepRunning() RunOption {
+	return func(cfg *runConfig) {
+		cfg.keepRunning = true
+	}
+}
+
+// RunError is the outcome of Run, errors.Is works with both the status and the error causing it.
+type RunError struct {
+	Code ErrCode
+	Err  error
+}
+
+func (e *RunError) Error() string {
+	return fmt.Sprintf("%v: %v", e.Code, e.Err)
+}
+
+func (e *RunError) Unwrap() []error {
+	return []error{e.Code, e.Err}
+}
+
+// ExitCode maps the outcome of Run to a process exit code, 0 for nil.
+func ExitCode(err error) int {
+	var code ErrCode
+
+	if err == nil {
+		return StatusOK.ExitCode()
+	} else if errors.As(err, &code) {
+		return code.ExitCode()
+	}
+	return 1
+}
+
+// Run creates a context, registers the beans and modules given, wires and starts it, then calls the runners.
+// It blocks until a signal is received, the runners complete or goCtx is done, and closes the context
+// within the shutdown timeout. The outcome is nil or a *RunError, use ExitCode to exit the process:
+//
+//	os.Exit(summer.ExitCode(summer.Run(summer.WithBeans(&Server{}, &Repository{}))))
+func Run(options ...RunOption) error {
+	cfg := &runConfig{
+		goCtx:           context.Background(),
+		args:            os.Args[1:],
+		signals:         []os.Signal{os.Interrupt, syscall.SIGTERM},
+		shutdownTimeout: DefaultShutdownTimeout,
+	}
+
+	for _, option := range options {
+		option(cfg)
+	}
+
+	runCtx, stopSignals := signal.NotifyContext(cfg.goCtx, cfg.signals...)
+	defer stopSignals()
+
+	ctx := New().(*contextManagerImpl)
+
+	var err error
+	var running <-chan error
+
+	if err = setupModules(ctx, cfg.modules); err == nil {
+		err = ctx.PerformAutoWiringContext(runCtx)
+	}
+
+	if err != nil {
+		err = &RunError{Code: StatusWiringFailed, Err: err}
+	} else if err = ctx.Start(runCtx); err != nil {
+		err = &RunError{Code: StatusStartFailed, Err: err}
+	} else {
+		running, err = ctx.awaitRunners(runCtx, cfg)
+	}
+
+	// a second signal terminates the process right away
+	stopSignals()
+
+	ctx.logger.Debug("shutting down", slog.Duration("timeout", cfg.shutdownTimeout))
+
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(cfg.goCtx), cfg.shutdownTimeout)
+	defer cancel()
+
+	// runners are told to return by runCtx, the beans they use are only destroyed once they have
+	if running != nil {
+		select {
+		case runnerErr := <-running:
+			if runnerErr != nil && !errors.Is(runnerErr, context.Canceled) {
+				err = &RunError{Code: StatusRunnerFailed, Err: runnerErr}
+			}
+		case <-shutdownCtx.Done():
+			err = &RunError{Code: StatusShutdownTimeout, Err: fmt.Errorf("runners still running: %w", shutdownCtx.Err())}
+		}
+	}
+
+	if closeErr := ctx.Close(shutdownCtx); closeErr == nil {
+		return err
+	} else if err != nil {
+		return &RunError{Code: err.(*RunError).Code, Err: errors.Join(err.(*RunError).Err, closeErr)}
+	} else if errors.Is(closeErr, context.DeadlineExceeded) {
+		return &RunError{Code: StatusShutdownTimeout, Err: closeErr}
+	} else {
+		return &RunError{Code: StatusShutdownFailed, Err: closeErr}
+	}
+}
+
+// setupModules calls the modules, a bean failed to be added panics, it is turned into an error.
+func setupModules(ctx ApplicationContextManager, modules []func(ctx ApplicationContextManager) error) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			if anError, ok := e.(error); ok {
+				err = anError
+			} else {
+				err = fmt.Errorf("%v", e)
+			}
+		}
+	}()
+
+	for _, module := range modules {
+		if err := module(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// awaitRunners calls the runners in the background, and waits for them (and a signal, if needed).
+// If runCtx is done before the runners return, the channel their outcome will be sent to is returned.
+func (ctx *contextManagerImpl) awaitRunners(runCtx context.Context, cfg *runConfig) (<-chan error, error) {
+	runners, err := ctx.runners()
+
+	if err != nil {
+		return nil, &RunError{Code: StatusRunnerFailed, Err: err}
+	} else if len(runners) == 0 {
+		<-runCtx.Done()
+		return nil, nil
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- ctx.callRunners(runCtx, runners, cfg.args)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return nil, &RunError{Code: StatusRunnerFailed, Err: err}
+		} else if cfg.keepRunning {
+			<-runCtx.Done()
+		}
+		return nil, nil
+	case <-runCtx.Done():
+		return done, nil
+	}
+}
+
+// runners lists wired beans implementing CommandLineRunner or ApplicationRunner, ordered by SummerOrder.
+func (ctx *contextManagerImpl) runners() ([]interface{}, error) {
+	var runners []interface{}
+
+	items := ctx.wiredItems(nil)
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return orderOf(items[i].Bean) < orderOf(items[j].Bean)
+	})
+
+	for _, item := range items {
+		if item.Scope != "" {
+			continue
+		} else if item.Lazy && !item.BeanType.Implements(commandLineRunnerType) && !item.BeanType.Implements(applicationRunnerType) {
+			continue
+		}
+
+		bean, err := ctx.readBean(item)
+
+		if err != nil {
+			return nil, err
+		}
+
+		switch bean.(type) {
+		case CommandLineRunner, ApplicationRunner:
+			runners = append(runners, bean)
+		}
+	}
+	return runners, nil
+}
+
+func (ctx *contextManagerImpl) callRunners(runCtx context.Context, runners []interface{}, args []string) error {
+	for _, runner := range runners {
+		var err error
+
+		ctx.logger.Debug("runner", slog.String("bean", fmt.Sprintf("%T", runner)))
+
+		switch r := runner.(type) {
+		case CommandLineRunner:
+			err = r.RunCommandLine(runCtx, args)
+		case ApplicationRunner:
+			err = r.RunApplication(runCtx, ctx)
+		}
+
+		if err != nil {
+			return fmt.Errorf("runner [%T]: %w", runner, err)
+		}
+	}
+	return nil
+}
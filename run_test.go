@@ -0,0 +1,70 @@
+package summer
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type runDatabase struct {
+	destroyed atomic.Bool
+}
+
+func (db *runDatabase) PreSummerDestroy() error {
+	db.destroyed.Store(true)
+	return nil
+}
+
+// keeps using the database for a while once asked to return
+type runWorker struct {
+	Database      *runDatabase `inject:"*"`
+	linger        time.Duration
+	usedDestroyed atomic.Bool
+	returned      atomic.Bool
+}
+
+func (w *runWorker) RunCommandLine(goCtx context.Context, args []string) error {
+	<-goCtx.Done()
+	time.Sleep(w.linger)
+
+	w.usedDestroyed.Store(w.Database.destroyed.Load())
+	w.returned.Store(true)
+	return goCtx.Err()
+}
+
+func runUntilCancelled(worker *runWorker, timeout time.Duration) error {
+	goCtx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+	return Run(WithContext(goCtx), WithBeans(&runDatabase{}, worker), WithShutdownTimeout(timeout))
+}
+
+func TestRunWaitsForRunnersBeforeClosing(t *testing.T) {
+	worker := &runWorker{linger: 100 * time.Millisecond}
+
+	if err := runUntilCancelled(worker, time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	if !worker.returned.Load() {
+		t.Fatal("Run returned before the runner")
+	} else if worker.usedDestroyed.Load() {
+		t.Fatal("beans destroyed while the runner was still using them")
+	}
+}
+
+func TestRunGivesUpOnRunnersAfterShutdownTimeout(t *testing.T) {
+	worker := &runWorker{linger: time.Second}
+	err := runUntilCancelled(worker, 100*time.Millisecond)
+
+	var runErr *RunError
+
+	if !errors.As(err, &runErr) || runErr.Code != StatusShutdownTimeout || ExitCode(err) != 5 {
+		t.Fatalf("unexpected outcome: %v", err)
+	}
+}
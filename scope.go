@@ -0,0 +1,23 @@
+package summer
+
+const (
+	// one instance shared by every injection point, the default
+	ScopeSingleton = `singleton`
+
+	// a fresh instance is created and wired for every injection point or Get call
+	ScopePrototype = `prototype`
+)
+
+// Scope decides when a scoped bean should be created. A scope can keep instances around
+// (per tenant, per job ...) or simply call create every time, like "prototype" does.
+// Scopes other than "singleton" and "prototype" have to be registered by RegisterScope before autowiring.
+type Scope interface {
+	// Get returns an instance of the bean, create builds and wires a new one.
+	Get(beanName string, create func() (interface{}, error)) (interface{}, error)
+}
+
+type prototypeScope struct{}
+
+func (prototypeScope) Get(_ string, create func() (interface{}, error)) (interface{}, error) {
+	return create()
+}
@@ -0,0 +1,137 @@
+package summer
+
+import (
+	"context"
+	"testing"
+)
+
+type scopeRepository struct{}
+
+// a prototype, every injection gets a copy of its own
+type scopeSession struct {
+	Repository  *scopeRepository `inject:"*"`
+	constructed int
+}
+
+func (s *scopeSession) PostSummerConstruct() {
+	s.constructed++
+}
+
+type scopeHandler struct {
+	Session *scopeSession `inject:"*"`
+}
+
+type scopeOtherHandler struct {
+	Session *scopeSession `inject:"*"`
+}
+
+func TestPrototypeScope(t *testing.T) {
+	ctx := New()
+	handler, other := &scopeHandler{}, &scopeOtherHandler{}
+	repository := &scopeRepository{}
+
+	ctx.Add(handler, other, repository)
+	ctx.AddWithScope(ScopePrototype, &scopeSession{})
+
+	if err := ctx.PerformAutoWiringContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if handler.Session == nil || other.Session == nil || handler.Session == other.Session {
+		t.Fatalf("injections share a prototype: %p %p", handler.Session, other.Session)
+	}
+
+	for _, session := range []*scopeSession{handler.Session, other.Session} {
+		if session.Repository != repository || session.constructed != 1 {
+			t.Errorf("a copy is not wired: %+v", *session)
+		}
+	}
+
+	first, _ := Get[*scopeSession](ctx)
+	second, _ := Get[*scopeSession](ctx)
+
+	if first == nil || first == second || first == handler.Session || first.Repository != repository {
+		t.Errorf("Get does not create a wired copy: %p %p", first, second)
+	}
+}
+
+func TestInScope(t *testing.T) {
+	ctx := New()
+	ctx.AddWithName("session", &scopeSession{}, InScope(ScopePrototype))
+	ctx.AddWithName("repository", &scopeRepository{}, InScope(ScopeSingleton))
+
+	if err := ctx.PerformAutoWiringContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	first, _ := ctx.GetByName("session")
+	second, _ := ctx.GetByName("session")
+
+	if first == second {
+		t.Error("a prototype bean is shared")
+	}
+
+	first, _ = ctx.GetByName("repository")
+	second, _ = ctx.GetByName("repository")
+
+	if first != second {
+		t.Error("a singleton bean is not shared")
+	}
+}
+
+// keeps one instance per tenant
+type tenantScope struct {
+	tenant    string
+	instances map[string]interface{}
+	created   int
+}
+
+func (scope *tenantScope) Get(beanName string, create func() (interface{}, error)) (interface{}, error) {
+	key := scope.tenant + "/" + beanName
+
+	if instance, found := scope.instances[key]; found {
+		return instance, nil
+	}
+
+	instance, err := create()
+
+	if err == nil {
+		scope.instances[key] = instance
+		scope.created++
+	}
+	return instance, err
+}
+
+func TestCustomScope(t *testing.T) {
+	ctx := New()
+	scope := &tenantScope{tenant: "acme", instances: map[string]interface{}{}}
+	handler, other := &scopeHandler{}, &scopeOtherHandler{}
+
+	ctx.RegisterScope("tenant", scope)
+	ctx.Add(handler, other, &scopeRepository{})
+	ctx.AddWithOptions(&scopeSession{}, InScope("tenant"))
+
+	if err := ctx.PerformAutoWiringContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if handler.Session != other.Session || scope.created != 1 {
+		t.Fatalf("the tenant scope is not used: %p %p, %d created", handler.Session, other.Session, scope.created)
+	}
+
+	scope.tenant = "globex"
+
+	if session, _ := Get[*scopeSession](ctx); session == handler.Session || scope.created != 2 || session.Repository == nil {
+		t.Fatalf("another tenant shares the instance: %d created", scope.created)
+	}
+}
+
+func TestUnknownScope(t *testing.T) {
+	ctx := New()
+	ctx.Add(&scopeHandler{}, &scopeRepository{})
+	ctx.AddWithOptions(&scopeSession{}, InScope("tenant"))
+
+	if err := ctx.PerformAutoWiringContext(context.Background()); err == nil {
+		t.Fatal("a bean of an unknown scope is injected")
+	}
+}
@@ -0,0 +1,115 @@
+package summer
+
+import (
+	"fmt"
+	"github.com/linuzilla/summer/gobean"
+	"reflect"
+)
+
+// states of a context, it only moves forward, but back to stateRegistering if autowiring fails
+const (
+	stateRegistering int32 = iota
+	stateWiring
+	stateFrozen // wired, nothing will be changed anymore, so reads need no lock
+)
+
+// readLock guards lookups, the returned function releases it.
+// Once frozen the context never changes, no lock is taken then.
+func (ctx *contextManagerImpl) readLock() func() {
+	if ctx.state.Load() == stateFrozen {
+		return func() {}
+	}
+	ctx.mu.RLock()
+	return ctx.mu.RUnlock
+}
+
+// checkRegistering tells whether beans may still be added or scopes registered.
+func (ctx *contextManagerImpl) checkRegistering() error {
+	switch ctx.state.Load() {
+	case stateWiring:
+		return ErrWiringInProgress
+	case stateFrozen:
+		return ErrFrozen
+	default:
+		return nil
+	}
+}
+
+// configure changes a setting of ctx, it panics once autowiring has started, since the setting is read without a lock then.
+func (ctx *contextManagerImpl) configure(setting string, change func()) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+
+	if err := ctx.checkRegistering(); err != nil {
+		panic(fmt.Errorf("can not set %s: %w", setting, err))
+	}
+	change()
+}
+
+// unlocked runs user code, such as a provider or PostSummerConstruct, with the lock released while autowiring,
+// so that it may look up beans, from any goroutine, without a deadlock.
+func (ctx *contextManagerImpl) unlocked(userCode func() error) error {
+	if !ctx.wiringLocked {
+		return userCode()
+	}
+
+	ctx.wiringLocked = false
+	ctx.mu.Unlock()
+
+	defer func() {
+		ctx.mu.Lock()
+		ctx.wiringLocked = true
+	}()
+	return userCode()
+}
+
+// wiredItems lists wired beans matching the type, those of the ancestors included, in registration order.
+// If modelType is nil, every wired bean of ctx itself is listed.
+func (ctx *contextManagerImpl) wiredItems(modelType reflect.Type) []*gobean.PopulateItem {
+	defer ctx.readLock()()
+
+	var items []*gobean.PopulateItem
+
+	if modelType != nil {
+		for _, item := range ctx.itemsOf(modelType) {
+			if item.Wired {
+				items = append(items, item)
+			}
+		}
+
+		for _, item := range ctx.parentItemsOf(modelType) {
+			if item.Wired && !ctx.shadowed(item) {
+				items = append(items, item)
+			}
+		}
+		return items
+	}
+
+	for e := ctx.items.Front(); e != nil; e = e.Next() {
+		if item := e.Value.(*gobean.PopulateItem); item.Wired {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// readBean is beanOf for lookups, a singleton never changes once wired, only scoped or lazy beans need the lock.
+func (ctx *contextManagerImpl) readBean(item *gobean.PopulateItem) (interface{}, error) {
+	if item.Scope == "" && !item.Lazy {
+		return item.Bean, nil
+	}
+
+	defer ctx.readLock()()
+	return ctx.beanOf(item)
+}
+
+// registerLocked adds an item unless autowiring has started.
+func (ctx *contextManagerImpl) registerLocked(item *gobean.PopulateItem, beanName string, reg *registration) (*gobean.PopulateItem, error) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+
+	if err := ctx.checkRegistering(); err != nil {
+		return nil, &BeanError{Name: beanName, Type: item.BeanType.String(), Source: item.Source, Err: fmt.Errorf("can not add bean: %w", err)}
+	}
+	return ctx.register(item, beanName, reg)
+}
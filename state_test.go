@@ -0,0 +1,191 @@
+package summer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+)
+
+type stressRepository struct {
+	Name string
+}
+
+type stressService struct {
+	Repository *stressRepository `inject:"*"`
+}
+
+func (s *stressService) PostSummerConstruct() {
+	if s.Repository == nil {
+		panic("repository not injected")
+	}
+}
+
+type stressNamed interface {
+	Named() string
+}
+
+type stressWorker struct {
+	Name       string
+	Repository *stressRepository `inject:"*"`
+}
+
+func (w *stressWorker) Named() string {
+	return w.Name
+}
+
+// lookups from other goroutines must neither race nor deadlock with autowiring, run with -race
+func TestConcurrentLookupsWhileAutowiring(t *testing.T) {
+	for round := 0; round < 20; round++ {
+		ctx := New()
+		ctx.Add(&stressRepository{Name: "repository"}, &stressService{})
+
+		for i := 0; i < 50; i++ {
+			ctx.AddWithName(fmt.Sprintf("named-%d", i), &stressWorker{Name: fmt.Sprintf("named-%d", i)})
+		}
+
+		start := make(chan struct{})
+		var wg sync.WaitGroup
+
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+				<-start
+
+				for i := 0; i < 100; i++ {
+					ctx.Get((*stressService)(nil))
+					ctx.GetByName("named-7")
+					ctx.ForEach((*stressNamed)(nil), func(data interface{}) {
+						data.(stressNamed).Named()
+					})
+					ctx.Each(func(data interface{}) {})
+				}
+			}()
+		}
+
+		close(start)
+
+		if err := <-ctx.Autowiring(func(err error) {}); err != nil {
+			t.Fatal(err)
+		}
+		wg.Wait()
+
+		if bean, err := ctx.Get((*stressService)(nil)); err != nil {
+			t.Fatal(err)
+		} else if bean.(*stressService).Repository.Name != "repository" {
+			t.Fatalf("unexpected repository %q", bean.(*stressService).Repository.Name)
+		}
+
+		if n := ctx.ForEach((*stressNamed)(nil), func(data interface{}) {}); n != 50 {
+			t.Fatalf("ForEach visited %d beans, want 50", n)
+		}
+	}
+}
+
+type addingBean struct {
+	ctx       ApplicationContextManager
+	recovered interface{}
+}
+
+func (b *addingBean) PostSummerConstruct() {
+	defer func() {
+		b.recovered = recover()
+	}()
+	b.ctx.Add(&stressRepository{})
+}
+
+func expectPanic(t *testing.T, target error, action func()) {
+	t.Helper()
+
+	defer func() {
+		t.Helper()
+
+		if r := recover(); r == nil {
+			t.Fatalf("expected a panic with %v", target)
+		} else if err, ok := r.(error); !ok || !errors.Is(err, target) {
+			t.Fatalf("expected a panic with %v, got %v", target, r)
+		}
+	}()
+	action()
+}
+
+func TestAddRejectedWhileWiring(t *testing.T) {
+	ctx := New()
+	bean := &addingBean{ctx: ctx}
+	ctx.Add(bean)
+
+	if err := ctx.PerformAutoWiringContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err, ok := bean.recovered.(error); !ok || !errors.Is(err, ErrWiringInProgress) {
+		t.Fatalf("expected a panic with %v, got %v", ErrWiringInProgress, bean.recovered)
+	}
+}
+
+func TestRejectedOnceFrozen(t *testing.T) {
+	ctx := New()
+	ctx.Add(&stressRepository{})
+
+	if err := ctx.PerformAutoWiringContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	expectPanic(t, ErrFrozen, func() { ctx.Add(&stressService{}) })
+	expectPanic(t, ErrFrozen, func() { ctx.AddWithName("another", &stressRepository{}) })
+	expectPanic(t, ErrFrozen, func() { ctx.Provide(func() *stressService { return &stressService{} }) })
+	expectPanic(t, ErrFrozen, func() { ctx.RegisterScope("request", nil) })
+}
+
+func TestSettersRejectedOnceFrozen(t *testing.T) {
+	ctx := New()
+
+	if err := ctx.PerformAutoWiringContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, set := range map[string]func(){
+		"SetLogger":                   func() { ctx.SetLogger(slog.Default()) },
+		"SetEnvironment":              func() { ctx.SetEnvironment(NewEnvironment()) },
+		"SetTagName":                  func() { ctx.SetTagName("wire") },
+		"SetValueTagName":             func() { ctx.SetValueTagName("property") },
+		"SetSetterNameFunc":           func() { ctx.SetSetterNameFunc(func(s string) string { return s }) },
+		"SetExportedVariableNameFunc": func() { ctx.SetExportedVariableNameFunc(func(s string) string { return s }) },
+		"SetPluginBeanNamePrefix":     func() { ctx.SetPluginBeanNamePrefix("p#") },
+		"SetActiveProfiles":           func() { ctx.SetActiveProfiles("test") },
+	} {
+		t.Run(name, func(t *testing.T) {
+			expectPanic(t, ErrFrozen, set)
+		})
+	}
+}
+
+type configuringBean struct {
+	ctx       ApplicationContextManager
+	recovered interface{}
+}
+
+func (b *configuringBean) PostSummerConstruct() {
+	defer func() {
+		b.recovered = recover()
+	}()
+	b.ctx.SetTagName("wire")
+}
+
+func TestSettersRejectedWhileWiring(t *testing.T) {
+	ctx := New()
+	bean := &configuringBean{ctx: ctx}
+	ctx.Add(bean)
+
+	if err := ctx.PerformAutoWiringContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err, ok := bean.recovered.(error); !ok || !errors.Is(err, ErrWiringInProgress) {
+		t.Fatalf("expected a panic with %v, got %v", ErrWiringInProgress, bean.recovered)
+	}
+}
@@ -2,62 +2,154 @@ package summer
 
 import (
 	"container/list"
+	"context"
+	"errors"
 	"fmt"
 	"github.com/linuzilla/summer/gobean"
 	"github.com/linuzilla/summer/utils"
 	"io/ioutil"
-	"log"
+	"log/slog"
 	"os"
-	"path/filepath"
-	"plugin"
 	"reflect"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 const DefaultInjectionTag = `inject`
 const DefaultPluginNamePrefix = `plugin#`
 
 type contextManagerImpl struct {
+	parent                   *contextManagerImpl // nil for a root context, see NewChild
 	items                    *list.List
 	itemsMap                 map[string]*gobean.PopulateItem
-	debug                    bool
+	index                    *typeIndex
+	logger                   *slog.Logger
+	logLevel                 *slog.LevelVar // of the default logger
 	injectionTag             string
+	valueTag                 string
+	environment              *Environment
 	pluginNamePrefix         string
 	setterNameFunc           func(variableName string) string
 	exportedVariableNameFunc func(variableName string) string
+	scopes                   map[string]Scope
+	conditions               map[*gobean.PopulateItem][]condition // not evaluated yet
+	activeProfiles           []string
+	skipped                  []SkippedBean
+	wiredOrder               []*gobean.PopulateItem // every dependency of a bean comes before it
+	closed                   bool
+	wiringCtx                context.Context
+	events                   *eventBus
+	health                   *HealthRegistry
+	mu                       sync.RWMutex // guards everything above, but once frozen nothing changes
+	state                    atomic.Int32
+	wiringLocked             bool // mu is held by autowiring, not released for user code
+	closeMu                  sync.Mutex
+	lifecycleMu              sync.Mutex   // Start and Stop
+	plugins                  []PluginInfo // tried by LoadPlugins, may be loaded at any time
+	pluginsMu                sync.Mutex
 }
 
-func (ctx *contextManagerImpl) addBean(bean interface{}) (*gobean.PopulateItem, error) {
-	if item, err := gobean.New(bean, 3, ctx.injectionTag); err != nil {
+func (ctx *contextManagerImpl) register(item *gobean.PopulateItem, beanName string, reg *registration) (*gobean.PopulateItem, error) {
+	item.Scope = reg.scope
+	item.Lazy = reg.lazy && reg.scope == ""
+
+	if beanName != "" {
+		// conditional beans may share a name, as long as only one of them remains
+		if existing, found := ctx.itemsMap[beanName]; !found {
+			ctx.itemsMap[beanName] = item
+		} else if len(reg.conditions) == 0 && len(ctx.conditions[existing]) == 0 {
+			return nil, &BeanError{Name: beanName, Type: item.BeanType.String(), Source: item.Source, Err: fmt.Errorf("%w, already registered at %s", ErrDuplicateName, existing.Source)}
+		}
+		item.Name = beanName
+	}
+
+	if len(reg.conditions) > 0 {
+		ctx.conditions[item] = reg.conditions
+	}
+
+	ctx.items.PushBack(item)
+	ctx.index.add(item)
+	return item, nil
+}
+
+func (ctx *contextManagerImpl) addBean(bean interface{}, beanName string, options []Option) (*gobean.PopulateItem, error) {
+	reg := newRegistration(options)
+
+	if item, err := gobean.New(bean, 3, ctx.injectionTag, ctx.valueTag); err != nil {
 		return nil, err
 	} else {
-		ctx.items.PushBack(item)
-		return item, nil
+		return ctx.registerLocked(item, beanName, reg)
+	}
+}
+
+func (ctx *contextManagerImpl) addProvider(constructor interface{}, beanName string, options []Option) (*gobean.PopulateItem, error) {
+	reg := newRegistration(options)
+
+	if item, err := gobean.NewProvider(constructor, reg.qualifiers, 3); err != nil {
+		return nil, err
+	} else {
+		return ctx.registerLocked(item, beanName, reg)
 	}
 }
 
 func (ctx *contextManagerImpl) Add(beans ...interface{}) ApplicationContextManager {
 	for _, bean := range beans {
-		if _, err := ctx.addBean(bean); err != nil {
+		if _, err := ctx.addBean(bean, "", nil); err != nil {
 			panic(err)
 		}
 	}
 	return ctx
 }
 
-func (ctx *contextManagerImpl) AddWithName(beanName string, bean interface{}) ApplicationContextManager {
-	if _, found, _ := ctx.getBeanByName(beanName); !found {
-		if item, err := ctx.addBean(bean); err != nil {
+func (ctx *contextManagerImpl) AddWithScope(scopeName string, beans ...interface{}) ApplicationContextManager {
+	for _, bean := range beans {
+		if _, err := ctx.addBean(bean, "", []Option{InScope(scopeName)}); err != nil {
 			panic(err)
-		} else {
-			ctx.itemsMap[beanName] = item
 		}
-	} else {
-		panic(fmt.Errorf("duplicate bean name:'%s'", beanName))
 	}
 	return ctx
 }
 
+func (ctx *contextManagerImpl) AddWithOptions(bean interface{}, options ...Option) ApplicationContextManager {
+	if _, err := ctx.addBean(bean, "", options); err != nil {
+		panic(err)
+	}
+	return ctx
+}
+
+func (ctx *contextManagerImpl) AddWithName(beanName string, bean interface{}, options ...Option) ApplicationContextManager {
+	if _, err := ctx.addBean(bean, beanName, options); err != nil {
+		panic(err)
+	}
+	return ctx
+}
+
+func (ctx *contextManagerImpl) Provide(constructor interface{}, options ...Option) ApplicationContextManager {
+	if _, err := ctx.addProvider(constructor, "", options); err != nil {
+		panic(err)
+	}
+	return ctx
+}
+
+func (ctx *contextManagerImpl) ProvideWithName(beanName string, constructor interface{}, options ...Option) ApplicationContextManager {
+	if _, err := ctx.addProvider(constructor, beanName, options); err != nil {
+		panic(err)
+	}
+	return ctx
+}
+
+func (ctx *contextManagerImpl) RegisterScope(scopeName string, scope Scope) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+
+	if err := ctx.checkRegistering(); err != nil {
+		panic(fmt.Errorf("can not register scope '%s': %w", scopeName, err))
+	}
+	ctx.scopes[scopeName] = scope
+}
+
 func (ctx *contextManagerImpl) assignable(item *gobean.PopulateItem, modelType reflect.Type) bool {
 	switch modelType.Kind() {
 	case reflect.Interface:
@@ -66,108 +158,100 @@ func (ctx *contextManagerImpl) assignable(item *gobean.PopulateItem, modelType r
 		}
 
 	case reflect.Struct:
-		if item.BeanType.Elem() == modelType {
+		if item.BeanType.Kind() == reflect.Ptr && item.BeanType.Elem() == modelType {
 			return true
 		}
 	}
 	return false
 }
 
-func (ctx *contextManagerImpl) findByType(modelType reflect.Type, singleMatchOnly bool, callback func(item *gobean.PopulateItem)) (*gobean.PopulateItem, int) {
-	var rc *gobean.PopulateItem = nil
+func (ctx *contextManagerImpl) findByType(modelType reflect.Type) (*gobean.PopulateItem, int) {
 	var candidate *gobean.PopulateItem = nil
 	matched := 0
 
-	for e := ctx.items.Front(); e != nil; e = e.Next() {
-		item := e.Value.(*gobean.PopulateItem)
-
-		duplicate := false
-
-		switch modelType.Kind() {
-		case reflect.Interface:
-			if item.BeanType.Implements(modelType) {
-				matched++
-				if item.Wired {
-					if callback != nil {
-						callback(item)
-					}
-					candidate = item
-				}
+	for _, item := range ctx.itemsOf(modelType) {
+		matched++
+		if item.Wired {
+			candidate = item
+		}
+	}
 
-				if rc == nil {
-					rc = item
-				} else {
-					duplicate = true
-				}
-			}
+	return candidate, matched
+}
 
-		case reflect.Struct:
-			if item.BeanType.Elem() == modelType {
-				matched++
-				if item.Wired {
-					if callback != nil {
-						callback(item)
-					}
-					candidate = item
-				}
-				if rc == nil {
-					rc = item
-				} else {
-					duplicate = true
-				}
-			}
-		}
+// candidatesOf describes every bean matching the type, for an ErrAmbiguousBean
+func (ctx *contextManagerImpl) candidatesOf(modelType reflect.Type) []string {
+	var candidates []string
 
-		if duplicate {
-			if singleMatchOnly {
-				if matched == 2 {
-					fmt.Println("Multiple match for [", modelType, "]:")
-					fmt.Println(">> ", rc.BeanType)
-				}
-				fmt.Println(">> ", item.BeanType)
-				fmt.Println("Consider using match by name instead.")
-			}
+	for _, item := range ctx.itemsOf(modelType) {
+		if item.Name != "" {
+			candidates = append(candidates, fmt.Sprintf("'%s' [%s]", item.Name, item.BeanType))
+		} else {
+			candidates = append(candidates, fmt.Sprintf("[%s]", item.BeanType))
 		}
-
 	}
+	return candidates
+}
 
-	return candidate, matched
+func (ctx *contextManagerImpl) resolveByType(modelType reflect.Type) (*gobean.PopulateItem, error) {
+	if item, matched := ctx.findByType(modelType); matched == 0 && ctx.parent != nil {
+		return ctx.parentResolveByType(modelType)
+	} else if matched == 0 {
+		return nil, &LookupError{Type: modelType, Err: ErrNoSuchBean}
+	} else if item == nil { // candidates are there, but not ready
+		return nil, &LookupError{Type: modelType, Matched: matched, Err: ErrNotWired}
+	} else if matched > 1 {
+		return nil, &LookupError{Type: modelType, Matched: matched, Err: ErrAmbiguousBean}
+	} else {
+		return item, nil
+	}
 }
 
 func (ctx *contextManagerImpl) Get(expectedTypeData interface{}) (interface{}, error) {
-	if item, matched := ctx.findByType(reflect.TypeOf(expectedTypeData).Elem(), true, nil); item != nil {
-		if matched > 1 {
-			return nil, fmt.Errorf("multiple match found")
-		} else {
-			if reflect.TypeOf(expectedTypeData).Kind() == reflect.Ptr {
-				if elem := reflect.ValueOf(expectedTypeData).Elem(); elem.CanSet() {
-					elem.Set(item.BeanValue)
-				}
-			}
-			return item.Bean, nil
+	if builtin := ctx.builtinOf(reflect.TypeOf(expectedTypeData).Elem()); builtin != nil {
+		if elem := reflect.ValueOf(expectedTypeData).Elem(); elem.CanSet() && reflect.TypeOf(builtin).AssignableTo(elem.Type()) {
+			elem.Set(reflect.ValueOf(builtin))
 		}
+		return builtin, nil
+	}
+
+	unlock := ctx.readLock()
+	item, err := ctx.resolveByType(reflect.TypeOf(expectedTypeData).Elem())
+	unlock()
+
+	if err != nil {
+		return nil, err
 	} else {
-		return nil, fmt.Errorf("no match found")
+		bean, err := ctx.readBean(item)
+
+		if err == nil && reflect.TypeOf(expectedTypeData).Kind() == reflect.Ptr {
+			if elem := reflect.ValueOf(expectedTypeData).Elem(); elem.CanSet() {
+				elem.Set(reflect.ValueOf(bean))
+			}
+		}
+		return bean, err
 	}
 }
 
 func (ctx *contextManagerImpl) ForEach(match interface{}, callback func(data interface{})) int {
 	rc := 0
-	ctx.findByType(reflect.TypeOf(match).Elem(), false, func(item *gobean.PopulateItem) {
-		callback(item.Bean)
-		rc++
-	})
+	for _, item := range ctx.wiredItems(reflect.TypeOf(match).Elem()) {
+		if bean, err := ctx.readBean(item); err == nil {
+			callback(bean)
+			rc++
+		}
+	}
 	return rc
 }
 
 func (ctx *contextManagerImpl) Each(callback func(data interface{})) int {
 	rc := 0
-	for e := ctx.items.Front(); e != nil; e = e.Next() {
-		item := e.Value.(*gobean.PopulateItem)
-
-		if item.Wired && callback != nil {
-			callback(item.Bean)
-			rc++
+	for _, item := range ctx.wiredItems(nil) {
+		if callback != nil {
+			if bean, err := ctx.readBean(item); err == nil {
+				callback(bean)
+				rc++
+			}
 		}
 	}
 	return rc
@@ -178,20 +262,24 @@ func (ctx *contextManagerImpl) getBeanByName(beanName string) (*gobean.PopulateI
 		if item.Wired {
 			return item, found, nil
 		} else {
-			return nil, found, fmt.Errorf("bean name '%s' not ready fully wired yet", beanName)
+			return nil, found, &LookupError{Name: beanName, Err: ErrNotWired}
 		}
+	} else if ctx.parent != nil {
+		return ctx.parentBeanByName(beanName)
 	} else {
-		return nil, found, fmt.Errorf("bean name '%s' not found", beanName)
+		return nil, found, &LookupError{Name: beanName, Err: ErrNoSuchBean}
 	}
 }
 
 func (ctx *contextManagerImpl) GetByName(beanName string) (interface{}, error) {
-	if item, found, err := ctx.getBeanByName(beanName); !found {
+	unlock := ctx.readLock()
+	item, _, err := ctx.getBeanByName(beanName)
+	unlock()
+
+	if err != nil {
 		return nil, err
-	} else if item != nil {
-		return item.Bean, nil
 	} else {
-		return nil, fmt.Errorf("bean name '%s' not found", beanName)
+		return ctx.readBean(item)
 	}
 }
 
@@ -199,100 +287,393 @@ func (ctx *contextManagerImpl) findWiredEntryByType(modelType reflect.Type) (mat
 	matchedItem = nil
 	matchCount = 0
 
-	for e := ctx.items.Front(); e != nil; e = e.Next() {
-		item := e.Value.(*gobean.PopulateItem)
+	for _, item := range ctx.itemsOf(modelType) {
+		matchCount++
+
+		if item.Wired && matchedItem == nil { // match first
+			matchedItem = item
+		}
+	}
 
-		switch modelType.Kind() {
-		case reflect.Interface:
-			if item.BeanType.Implements(modelType) {
-				matchCount++
+	if matchCount == 0 {
+		return ctx.parentWiredEntryByType(modelType)
+	}
+	return matchedItem, matchCount
+}
 
-				if item.Wired && matchedItem == nil { // match first
-					matchedItem = item
-				}
+// beanOf returns the bean held by a wired item, scoped items get their instance from the scope.
+func (ctx *contextManagerImpl) beanOf(item *gobean.PopulateItem) (interface{}, error) {
+	if ctx.parent != nil {
+		if owner := ctx.ownerOf(item); owner != ctx { // a scoped bean is wired within its own context
+			return owner.readBean(item)
+		}
+	}
+
+	if item.Scope == "" {
+		if item.Lazy {
+			if err := ctx.initLazy(item); err != nil {
+				return nil, err
 			}
+		}
+		return item.Bean, nil
+	} else if scope, found := ctx.scopes[item.Scope]; !found {
+		return nil, fmt.Errorf("%s: unknown scope '%s'", item.Source, item.Scope)
+	} else {
+		beanName := item.Name
 
-		case reflect.Struct:
-			if item.BeanType.Elem() == modelType {
-				matchCount++
-				if item.Wired && matchedItem == nil { // match first
-					matchedItem = item
-				}
+		if beanName == "" {
+			beanName = item.BeanType.String()
+		}
+		return scope.Get(beanName, func() (interface{}, error) {
+			return ctx.instantiate(item)
+		})
+	}
+}
+
+// resolveDependency finds the wired bean for a field, used once autowiring is done.
+// an optional field without any candidate gets a nil item and no error.
+func (ctx *contextManagerImpl) resolveDependency(elemField *gobean.ElementField) (*gobean.PopulateItem, error) {
+	var err error
+
+	if elemField.Tag.ByType {
+		elemFieldType := fieldModelType(elemField)
+
+		if matchedItem, cnt := ctx.findWiredEntryByType(elemFieldType); cnt > 1 {
+			return nil, &LookupError{Type: elemFieldType, Matched: cnt, Err: ErrAmbiguousBean}
+		} else if matchedItem != nil {
+			return matchedItem, nil
+		} else if cnt > 0 {
+			err = &LookupError{Type: elemFieldType, Matched: cnt, Err: ErrNotWired}
+		} else {
+			err = &LookupError{Type: elemFieldType, Err: ErrNoSuchBean}
+		}
+	} else if matchedItem, found, lookupErr := ctx.getBeanByName(elemField.Tag.Name); found {
+		return matchedItem, lookupErr
+	} else {
+		err = lookupErr
+	}
+
+	if elemField.Tag.Fallback != "" {
+		if matchedItem, found, lookupErr := ctx.getBeanByName(elemField.Tag.Fallback); found || !elemField.Tag.Optional {
+			return matchedItem, lookupErr
+		}
+	}
+
+	if elemField.Tag.Optional {
+		return nil, nil
+	}
+	return nil, err
+}
+
+// instantiate creates and wires a new instance of a scoped item.
+func (ctx *contextManagerImpl) instantiate(item *gobean.PopulateItem) (interface{}, error) {
+	instance := item.NewInstance()
+
+	for _, elemField := range instance.Fields {
+		if isProviderField(elemField) {
+			if err := ctx.injectProvider(instance, elemField); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if ctx.isBuiltinField(elemField) {
+			if err := ctx.injectBuiltin(instance, elemField); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if !elemField.IsCollection() {
+		} else if matchedItems, ready := ctx.collectionMatches(item, elemField); !ready {
+			return nil, ctx.injectionError(instance, elemField, ErrNotWired)
+		} else if err := ctx.injectCollection(instance, elemField, matchedItems); err != nil {
+			return nil, err
+		} else {
+			continue
+		}
+
+		if matchedItem, err := ctx.resolveDependency(elemField); err != nil {
+			return nil, ctx.injectionError(instance, elemField, err)
+		} else if matchedItem == nil {
+			if err := ctx.markFieldWired(instance, elemField, nil, "optional, left untouched"); err != nil {
+				return nil, err
 			}
+		} else if err := ctx.injectMatchedBean(instance, elemField, matchedItem, matchedItem.Name != ""); err != nil {
+			return nil, err
 		}
 	}
 
-	return matchedItem, matchCount
+	if _, err := ctx.completeItem(instance); err != nil {
+		return nil, err
+	}
+	return instance.Bean, nil
 }
 
-func (ctx *contextManagerImpl) setValueToField(item *gobean.PopulateItem, elemField *gobean.ElementField, matchedItem *gobean.PopulateItem) error {
+func (ctx *contextManagerImpl) setValueToField(item *gobean.PopulateItem, elemField *gobean.ElementField, bean interface{}) error {
 	field := elemField.FieldValue
 	elemFieldStruct := elemField.StructField
 
 	setterMethodName := ctx.setterNameFunc(elemFieldStruct.Name)
 
-	setter := item.BeanValue.MethodByName(setterMethodName)
+	var setter reflect.Value
+
+	if item.Meta == nil { // arguments of provider have no setter
+	} else if method, found := item.Meta.Setter(setterMethodName); found {
+		setter = item.BeanValue.Method(method.Index)
+	}
 
 	if setter.IsValid() {
-		if elemFieldStruct.Type.Kind() == reflect.Ptr {
-			if elemFieldStruct.Type == matchedItem.BeanType {
-				setter.Interface().(func(interface{}))(matchedItem.Bean)
-			} else {
-				log.Fatal("Autowire", elemFieldStruct.Type, "via", setterMethodName, "on", item.BeanType)
-			}
-		} else {
-			setter.Interface().(func(interface{}))(matchedItem.Bean)
+		beanValue := reflect.ValueOf(bean)
+
+		if setterType := setter.Type(); setterType.NumIn() != 1 || !beanValue.Type().AssignableTo(setterType.In(0)) {
+			return ctx.injectionError(item, elemField, fmt.Errorf("%w: %s%s can not take [%s]", ErrSetterSignature, setterMethodName, setterType.String()[len("func"):], beanValue.Type()))
 		}
+		setter.Call([]reflect.Value{beanValue})
 	} else if field.CanSet() {
-		field.Set(matchedItem.BeanValue)
+		field.Set(reflect.ValueOf(bean))
 	} else {
-		return fmt.Errorf("%s: No setter (%s) or structField not settable", elemFieldStruct.Type, setterMethodName)
+		return ctx.injectionError(item, elemField, fmt.Errorf("%w: neither %s() found nor field exported", ErrNotSettable, setterMethodName))
 	}
 
 	return nil
 }
 
+// injectionError wraps err with the item and field it happened on
+func (ctx *contextManagerImpl) injectionError(item *gobean.PopulateItem, elemField *gobean.ElementField, err error) *InjectionError {
+	return &InjectionError{
+		BeanType:  item.BeanType.String(),
+		Source:    item.Source,
+		Field:     elemField.StructField.Name,
+		FieldType: elemField.StructField.Type.String(),
+		Tag:       elemField.Tag.Raw,
+		Location:  elemField.FullName(ctx.injectionTag),
+		Err:       err,
+	}
+}
+
 func (ctx *contextManagerImpl) injectMatchedBean(item *gobean.PopulateItem, elemField *gobean.ElementField, matchedItem *gobean.PopulateItem, byName bool) error {
-	if err := ctx.setValueToField(item, elemField, matchedItem); err != nil {
-		fmt.Println(err)
+	if item.Scope != "" {
+		// only the template of a scoped bean, every instance will be wired when created
+	} else if bean, err := ctx.beanOf(matchedItem); err != nil {
+		return err
+	} else if err := ctx.setValueToField(item, elemField, bean); err != nil {
+		return err
+	}
+
+	if byName {
+		return ctx.markFieldWired(item, elemField, []*gobean.PopulateItem{matchedItem}, "by name: ["+matchedItem.Name+"]")
+	} else {
+		return ctx.markFieldWired(item, elemField, []*gobean.PopulateItem{matchedItem}, "")
+	}
+}
+
+func (ctx *contextManagerImpl) injectCollection(item *gobean.PopulateItem, elemField *gobean.ElementField, matchedItems []*gobean.PopulateItem) error {
+	if item.Scope != "" {
+		// only the template of a scoped bean, every instance will be wired when created
+	} else if collection, err := ctx.collectionOf(elemField.StructField.Type, matchedItems); err != nil {
+		return ctx.injectionError(item, elemField, err)
+	} else if err := ctx.setValueToField(item, elemField, collection.Interface()); err != nil {
 		return err
-	} else if !elemField.Wired {
+	}
+
+	return ctx.markFieldWired(item, elemField, matchedItems, fmt.Sprintf("collection of %d", len(matchedItems)))
+}
+
+func (ctx *contextManagerImpl) markFieldWired(item *gobean.PopulateItem, elemField *gobean.ElementField, targets []*gobean.PopulateItem, how string) error {
+	if !elemField.Wired {
 		elemField.Wired = true
+		elemField.Targets = targets
 		item.WiredCount++
-		if item.CheckIsWired() {
-			if postConstructable, ok := item.Bean.(HavePostConstruct); ok {
-				if ctx.debug {
-					fmt.Printf("PostConstruct: %s\n", item.BeanType.String())
-				}
-				postConstructable.PostSummerConstruct()
-			}
+
+		if how != "" {
+			ctx.logger.Debug("autowired", fieldAttrs(item, elemField, slog.String("how", how))...)
+		} else {
+			ctx.logger.Debug("autowired", fieldAttrs(item, elemField)...)
 		}
 
-		if ctx.debug {
-			if byName {
-				fmt.Printf("Auto wire for [%s] : [%s] by name: [%s]\n", item.BeanType.String(), elemField.StructField.Type.String(), elemField.TagValue)
-			} else {
-				fmt.Printf("Auto wire for [%s] : [%s]\n", item.BeanType.String(), elemField.StructField.Type.String())
-			}
+		if _, err := ctx.completeItem(item); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
+// collectionMatches returns every bean (but the owner itself, and unnamed ones for a map) a collection field should receive,
+// in registration order, ready is false if some of them are not wired yet.
+func (ctx *contextManagerImpl) collectionMatches(owner *gobean.PopulateItem, elemField *gobean.ElementField) (matchedItems []*gobean.PopulateItem, ready bool) {
+	ready = true
+	isMap := elemField.StructField.Type.Kind() == reflect.Map
+
+	for _, item := range ctx.itemsOf(elemField.ModelType()) {
+		if item != owner && (!isMap || item.Name != "") {
+			matchedItems = append(matchedItems, item)
+			ready = ready && item.Wired
+		}
+	}
+
+	// beans of the ancestors come after, unless shadowed by name
+	for _, item := range ctx.parentItemsOf(elemField.ModelType()) {
+		if (!isMap || item.Name != "") && !ctx.shadowed(item) {
+			matchedItems = append(matchedItems, item)
+			ready = ready && item.Wired
+		}
+	}
+	return matchedItems, ready
+}
+
+func orderOf(bean interface{}) int {
+	if ordered, ok := bean.(HaveOrder); ok {
+		return ordered.SummerOrder()
+	}
+	return 0
+}
+
+// collectionOf builds a slice sorted by SummerOrder (ties keep registration order), or a map keyed by bean name
+// (beans added without a name are left out). Beans are sorted once created, a provided bean is unknown before.
+func (ctx *contextManagerImpl) collectionOf(collectionType reflect.Type, matchedItems []*gobean.PopulateItem) (reflect.Value, error) {
+	var collection reflect.Value
+
+	switch {
+	case collectionType.Kind() == reflect.Slice:
+		collection = reflect.MakeSlice(collectionType, 0, len(matchedItems))
+	case collectionType.Key().Kind() == reflect.String:
+		collection = reflect.MakeMapWithSize(collectionType, len(matchedItems))
+	default:
+		return collection, fmt.Errorf("key of map should be a string")
+	}
+
+	var beans []interface{}
+
+	for _, matchedItem := range matchedItems {
+		if bean, err := ctx.beanOf(matchedItem); err != nil {
+			return collection, err
+		} else if collectionType.Kind() == reflect.Slice {
+			beans = append(beans, bean)
+		} else if matchedItem.Name != "" {
+			collection.SetMapIndex(reflect.ValueOf(matchedItem.Name).Convert(collectionType.Key()), reflect.ValueOf(bean))
+		}
+	}
+
+	sort.SliceStable(beans, func(i, j int) bool {
+		return orderOf(beans[i]) < orderOf(beans[j])
+	})
+
+	for _, bean := range beans {
+		collection = reflect.Append(collection, reflect.ValueOf(bean))
+	}
+	return collection, nil
+}
+
+// completeItem calls the provider (if any) and PostSummerConstruct once every field of the item is wired.
+func (ctx *contextManagerImpl) completeItem(item *gobean.PopulateItem) (bool, error) {
+	if item.Wired || item.WiredCount != len(item.Fields) {
+		return false, nil
+	}
+
+	if item.IsProvider() && !item.BeanValue.IsValid() && item.Scope == "" && !item.Lazy {
+		ctx.logger.Debug("provide", beanAttrs(item)...)
+		if err := ctx.unlocked(item.Construct); err != nil {
+			return false, &BeanError{Name: item.Name, Type: item.BeanType.String(), Source: item.Source, Err: err}
+		}
+	}
+
+	// the bean is visible to lookups only after its post construct
+	if item.IsReady() && item.Scope == "" && !item.Lazy {
+		if err := ctx.unlocked(func() error { return ctx.postConstruct(item) }); err != nil {
+			return false, &PostConstructError{Source: item.Source, Dependents: ctx.dependentsOf(item), Err: err}
+		}
+	}
+	return item.CheckIsWired(), nil
+}
+
+func (ctx *contextManagerImpl) postConstruct(item *gobean.PopulateItem) error {
+	if postConstructable, ok := item.Bean.(HavePostConstruct); ok {
+		ctx.logger.Debug("post construct", beanAttrs(item, slog.String("method", "PostSummerConstruct"))...)
+		postConstructable.PostSummerConstruct()
+	}
+
+	if postConstructable, ok := item.Bean.(HavePostConstructE); ok {
+		ctx.logger.Debug("post construct", beanAttrs(item, slog.String("method", "PostSummerConstructE"))...)
+		if err := postConstructable.PostSummerConstructE(); err != nil {
+			return err
+		}
+	}
+
+	if postConstructable, ok := item.Bean.(HavePostConstructCtx); ok {
+		ctx.logger.Debug("post construct", beanAttrs(item, slog.String("method", "PostSummerConstructCtx"))...)
+		if err := postConstructable.PostSummerConstructCtx(ctx.wiringCtx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dependsOn tells whether a field would be satisfied by the item
+func (ctx *contextManagerImpl) dependsOn(elemField *gobean.ElementField, item *gobean.PopulateItem) bool {
+	if item.Name != "" && (elemField.Tag.Name == item.Name || elemField.Tag.Fallback == item.Name) {
+		return true
+	}
+	return elemField.Tag.ByType && ctx.assignable(item, elemField.ModelType())
+}
+
+// dependentsOf lists the sources of beans depending on the item, directly or not, nearest first.
+func (ctx *contextManagerImpl) dependentsOf(item *gobean.PopulateItem) []string {
+	var dependents []string
+
+	visited := map[*gobean.PopulateItem]bool{item: true}
+	queue := []*gobean.PopulateItem{item}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		for e := ctx.items.Front(); e != nil; e = e.Next() {
+			candidate := e.Value.(*gobean.PopulateItem)
+
+			if visited[candidate] {
+				continue
+			}
+
+			for _, elemField := range candidate.Fields {
+				if ctx.dependsOn(elemField, current) {
+					visited[candidate] = true
+					queue = append(queue, candidate)
+					dependents = append(dependents, candidate.Source)
+					break
+				}
+			}
+		}
+	}
+	return dependents
+}
+
 func (ctx *contextManagerImpl) injectField(item *gobean.PopulateItem, elemField *gobean.ElementField) (bool, error) {
 	haveInjection := false
 
 	switch {
-	case elemField.TagValue == `*`: // injectMatchedBean by type
+	case isProviderField(elemField): // resolved when Provider.Get is called
+		if err := ctx.injectProvider(item, elemField); err != nil {
+			return false, err
+		}
+		haveInjection = true
 
-		elemFieldType := elemField.StructField.Type
+	case elemField.IsCollection(): // every bean matched by type
+		if matchedItems, ready := ctx.collectionMatches(item, elemField); ready {
+			if err := ctx.injectCollection(item, elemField, matchedItems); err != nil {
+				return false, err
+			}
+			haveInjection = true
+		}
 
-		if elemField.StructField.Type.Kind() == reflect.Interface {
-		} else {
-			elemFieldType = elemField.StructField.Type.Elem()
+	case ctx.isBuiltinField(elemField): // not a bean, see builtinOf
+		if err := ctx.injectBuiltin(item, elemField); err != nil {
+			return false, err
 		}
+		haveInjection = true
 
-		matchedItem, cnt := ctx.findWiredEntryByType(elemFieldType)
+	case elemField.Tag.ByType: // injectMatchedBean by type
+		matchedItem, cnt := ctx.findWiredEntryByType(elemField.ModelType())
 
 		switch {
 		case cnt == 1 && matchedItem != nil:
@@ -302,75 +683,236 @@ func (ctx *contextManagerImpl) injectField(item *gobean.PopulateItem, elemField
 			haveInjection = true
 
 		case cnt > 1:
-			fmt.Printf("Number of matched item: %d (structField index=%d)\n", cnt, elemField.Index)
+			return false, ctx.injectionError(item, elemField, &LookupError{
+				Type:       elemField.ModelType(),
+				Matched:    cnt,
+				Candidates: ctx.candidatesOf(elemField.ModelType()),
+				Err:        ErrAmbiguousBean,
+			})
 
 		case cnt == 0:
-			return false, fmt.Errorf("%s: no suitable bean\n", elemField.FullName(ctx.injectionTag))
+			return ctx.injectFallback(item, elemField, ctx.injectionError(item, elemField, &LookupError{Type: elemField.ModelType(), Err: ErrNoSuchBean}))
 		}
 
 	default: // injectMatchedBean by name
-		if matchedItem, found, err := ctx.getBeanByName(elemField.TagValue); matchedItem != nil {
+		if matchedItem, found, err := ctx.getBeanByName(elemField.Tag.Name); matchedItem != nil {
 			if err := ctx.injectMatchedBean(item, elemField, matchedItem, true); err != nil {
 				return haveInjection, err
 			}
 			haveInjection = true
 		} else if found {
 		} else {
-			ctx.dumpPendingInjectionField(item)
-			return haveInjection, fmt.Errorf("%s: %s", elemField.FullName(ctx.injectionTag), err)
+			return ctx.injectFallback(item, elemField, ctx.injectionError(item, elemField, fmt.Errorf("%w%s", err, ctx.skippedHint(elemField.Tag.Name))))
 		}
 
 	}
 	return haveInjection, nil
 }
 
-func (ctx *contextManagerImpl) dumpPendingInjectionField(item *gobean.PopulateItem) {
-	fmt.Printf(">> %s\n", item.Source)
-	for _, elemField := range item.Fields {
-		if !elemField.Wired {
-			fmt.Printf(".... %s\n", elemField.FullName(ctx.injectionTag))
+// injectFallback deals with a field without any candidate: inject the fallback bean,
+// leave it untouched if optional, or give up with notFound.
+func (ctx *contextManagerImpl) injectFallback(item *gobean.PopulateItem, elemField *gobean.ElementField, notFound error) (bool, error) {
+	if elemField.Tag.Fallback != "" {
+		if matchedItem, found, err := ctx.getBeanByName(elemField.Tag.Fallback); matchedItem != nil {
+			return true, ctx.injectMatchedBean(item, elemField, matchedItem, true)
+		} else if found {
+			return false, nil // wait for the fallback to be wired
+		} else if !elemField.Tag.Optional {
+			return false, ctx.injectionError(item, elemField, fmt.Errorf("fallback %w%s", err, ctx.skippedHint(elemField.Tag.Fallback)))
+		}
+	}
+
+	if elemField.Tag.Optional {
+		return true, ctx.markFieldWired(item, elemField, nil, "optional, left untouched")
+	}
+
+	return false, notFound
+}
+
+// injectValues fills every `value` field from the environment, reporting every failure at once.
+func (ctx *contextManagerImpl) injectValues() error {
+	var errs []error
+
+	for e := ctx.items.Front(); e != nil; e = e.Next() {
+		item := e.Value.(*gobean.PopulateItem)
+
+		for _, valueField := range item.Values {
+			if valueField.Injected {
+			} else if !valueField.FieldValue.CanSet() {
+				errs = append(errs, fmt.Errorf("%s: %w", valueField.FullName(ctx.valueTag), ErrNotSettable))
+			} else if err := bindValue(ctx.environment, valueField.FieldValue, valueField.TagValue); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", valueField.FullName(ctx.valueTag), err))
+			} else {
+				valueField.Injected = true
+
+				ctx.logger.Debug("value injected", beanAttrs(item,
+					slog.String("field", valueField.StructField.Name),
+					slog.String("tag", valueField.TagValue))...)
+			}
 		}
 	}
+	return errors.Join(errs...)
 }
 
-func (ctx *contextManagerImpl) dumpPendingInjection() {
+// unsatisfiedDependencies reports fields failed to be injected, and fields still waiting for a bean never wired.
+func (ctx *contextManagerImpl) unsatisfiedDependencies(failedFields map[*gobean.ElementField]error, failedItems map[*gobean.PopulateItem]bool) error {
+	var fields []string
+	var errs []error
+
 	for e := ctx.items.Front(); e != nil; e = e.Next() {
-		if item, ok := e.Value.(*gobean.PopulateItem); ok {
-			if !item.Wired {
-				ctx.dumpPendingInjectionField(item)
+		item := e.Value.(*gobean.PopulateItem)
+
+		if failedItems[item] {
+			continue
+		}
+
+		for _, elemField := range item.Fields {
+			if err := failedFields[elemField]; err != nil {
+				errs = append(errs, err)
+			} else if !elemField.Wired {
+				fields = append(fields, elemField.FullName(ctx.injectionTag))
 			}
 		}
 	}
+
+	if len(fields) == 0 && len(errs) == 0 {
+		return nil
+	}
+	return &UnsatisfiedDependencyError{Fields: fields, Errs: errs}
+}
+
+// performDependencyInjection publishes ContextWired once the context is frozen, an error of a listener is returned
+// but the context stays frozen.
+func (ctx *contextManagerImpl) performDependencyInjection(goCtx context.Context) error {
+	if wired, err := ctx.freeze(goCtx); err != nil {
+		return err
+	} else if wired {
+		return ctx.events.Publish(goCtx, ContextWired{Context: ctx})
+	}
+	return nil
 }
 
-func (ctx *contextManagerImpl) performDependencyInjection() error {
+// freeze holds the lock while wiring (except for user code), a successful autowiring
+// freezes the context, a failed one allows beans to be added and autowiring to be performed again.
+func (ctx *contextManagerImpl) freeze(goCtx context.Context) (bool, error) {
+	ctx.mu.Lock()
+	defer ctx.mu.Unlock()
+
+	switch ctx.state.Load() {
+	case stateFrozen:
+		return false, nil
+	case stateWiring:
+		return false, ErrWiringInProgress
+	}
+
+	ctx.state.Store(stateWiring)
+	ctx.wiringLocked = true
+
+	err := ctx.wire(goCtx)
+
+	// lazy and scoped beans may be created long after autowiring, never cancel them
+	ctx.wiringCtx = context.WithoutCancel(goCtx)
+	ctx.wiringLocked = false
+
+	if err != nil {
+		ctx.state.Store(stateRegistering)
+		return false, err
+	}
+	ctx.state.Store(stateFrozen)
+	return true, nil
+}
+
+// wire wires as much as possible, every problem found is reported at once by errors.Join.
+// A bean with a failed field, value or post construct is never completed, beans depending on it are reported as unsatisfied.
+func (ctx *contextManagerImpl) wire(goCtx context.Context) error {
+	ctx.wiringCtx = goCtx
+
+	if err := ctx.applyConditions(); err != nil {
+		return err
+	}
+
+	var errs []error
+
+	failedFields := map[*gobean.ElementField]error{}
+	failedItems := map[*gobean.PopulateItem]bool{}
+
+	if err := ctx.injectValues(); err != nil {
+		errs = append(errs, err)
+
+		for e := ctx.items.Front(); e != nil; e = e.Next() {
+			item := e.Value.(*gobean.PopulateItem)
+
+			for _, valueField := range item.Values {
+				if !valueField.Injected {
+					failedItems[item] = true
+				}
+			}
+		}
+	}
+
+	// dependencies first, everything able to be wired is wired by the first pass,
+	// another pass only runs if some beans are left, to find out none of them can be wired
+	order, err := ctx.detectCycles()
+
+	if err != nil {
+		return errors.Join(append(errs, err)...)
+	}
+
 	for i := 1; true; i++ {
+		if err := goCtx.Err(); err != nil {
+			return errors.Join(append(errs, err)...)
+		}
+
 		done := true
 		makeProgress := false
 
-		for e := ctx.items.Front(); e != nil; e = e.Next() {
-			if item, ok := e.Value.(*gobean.PopulateItem); ok {
-				if !item.Wired {
-					done = false
-
-					for _, elemField := range item.Fields {
-						if !elemField.Wired {
-							if haveInject, err := ctx.injectField(item, elemField); err != nil {
-								return err
-							} else if haveInject {
-								makeProgress = true
-							}
+		for _, item := range order {
+			if !item.Wired && !failedItems[item] {
+				for _, elemField := range item.Fields {
+					if elemField.Wired || failedFields[elemField] != nil || failedItems[item] {
+					} else if haveInject, err := ctx.injectField(item, elemField); err != nil {
+						ctx.logger.Debug("injection failed", fieldAttrs(item, elemField, slog.Any("error", err))...)
+
+						if elemField.Wired { // injected, but the item failed to complete
+							errs = append(errs, err)
+							failedItems[item] = true
+						} else {
+							failedFields[elemField] = err
 						}
+					} else if haveInject {
+						makeProgress = true
 					}
 				}
+
+				if failedItems[item] {
+					continue
+				}
+
+				// nothing to inject, or provider without any argument
+				if completed, err := ctx.completeItem(item); err != nil {
+					errs = append(errs, err)
+					failedItems[item] = true
+				} else if completed {
+					makeProgress = true
+				}
+
+				if item.Wired {
+					ctx.wiredOrder = append(ctx.wiredOrder, item)
+					ctx.events.subscribeBean(item)
+				} else {
+					done = false // waiting for a bean, or some field failed
+				}
 			}
 		}
 
 		if done {
-			return nil
+			ctx.logger.Debug("autowiring done", slog.Int("passes", i), slog.Int("beans", len(order)))
+			return errors.Join(errs...)
 		} else if !makeProgress {
-			ctx.dumpPendingInjection()
-			return fmt.Errorf("failed to autowiring")
+			if err := ctx.unsatisfiedDependencies(failedFields, failedItems); err != nil {
+				errs = append(errs, err)
+			}
+			return errors.Join(errs...)
 		}
 	}
 	return nil
@@ -380,7 +922,7 @@ func (ctx *contextManagerImpl) Autowiring(callback func(err error)) chan error {
 	errorChannel := make(chan error)
 
 	go func() {
-		err := ctx.performDependencyInjection()
+		err := ctx.performDependencyInjection(context.Background())
 		callback(err)
 		errorChannel <- err
 	}()
@@ -388,45 +930,35 @@ func (ctx *contextManagerImpl) Autowiring(callback func(err error)) chan error {
 }
 
 func (ctx *contextManagerImpl) PerformAutoWiring(onError func(err error)) ApplicationContextManager {
-	if err := ctx.performDependencyInjection(); err != nil {
+	if err := ctx.performDependencyInjection(context.Background()); err != nil {
 		if onError != nil {
 			onError(err)
 		} else {
-			log.Fatal(err)
+			panic(err)
 		}
 	}
 	return ctx
 }
 
+func (ctx *contextManagerImpl) PerformAutoWiringContext(goCtx context.Context) error {
+	return ctx.performDependencyInjection(goCtx)
+}
+
 func (ctx *contextManagerImpl) LoadPlugins(path string, callback func(beanName string, file string, module interface{}, err error)) error {
 	if files, err := ioutil.ReadDir(path); err != nil {
 		return err
 	} else {
+		var candidates []*pluginCandidate
+
 		Stream(files).Filter(func(i interface{}) bool {
 			return strings.HasSuffix(i.(os.FileInfo).Name(), `.so`)
 		}).Map(func(i interface{}) interface{} {
 			return i.(os.FileInfo).Name()
 		}).ForEach(func(i interface{}) {
-			moduleName := i.(string)
-			exportedVariableName := ctx.exportedVariableNameFunc(moduleName)
-			beanName := ctx.pluginNamePrefix + exportedVariableName
-
-			if plug, err := plugin.Open(filepath.Join(path, moduleName)); err != nil {
-				if callback != nil {
-					callback(beanName, moduleName, nil, err)
-				}
-			} else {
-				module, err := plug.Lookup(exportedVariableName)
-
-				if err == nil {
-					ctx.AddWithName(beanName, module)
-				}
-
-				if callback != nil {
-					callback(beanName, moduleName, module, err)
-				}
-			}
+			candidates = append(candidates, ctx.pluginCandidate(path, i.(string)))
 		})
+
+		ctx.loadPlugins(candidates, callback)
 	}
 	return nil
 }
@@ -434,35 +966,65 @@ func (ctx *contextManagerImpl) LoadPlugins(path string, callback func(beanName s
 // Setters
 
 func (ctx *contextManagerImpl) SetExportedVariableNameFunc(function func(string) string) {
-	ctx.exportedVariableNameFunc = function
+	ctx.configure("exported variable name function", func() {
+		ctx.exportedVariableNameFunc = function
+	})
 }
 
 func (ctx *contextManagerImpl) SetSetterNameFunc(function func(string) string) {
-	ctx.setterNameFunc = function
+	ctx.configure("setter name function", func() {
+		ctx.setterNameFunc = function
+	})
 }
 
 func (ctx *contextManagerImpl) SetTagName(tagName string) {
-	ctx.injectionTag = tagName
+	ctx.configure("tag name", func() {
+		ctx.injectionTag = tagName
+	})
 }
 
-func (ctx *contextManagerImpl) SetPluginBeanNamePrefix(prefix string) {
-	ctx.pluginNamePrefix = prefix
+func (ctx *contextManagerImpl) SetValueTagName(tagName string) {
+	ctx.configure("value tag name", func() {
+		ctx.valueTag = tagName
+	})
+}
+
+func (ctx *contextManagerImpl) Environment() *Environment {
+	return ctx.environment
+}
+
+func (ctx *contextManagerImpl) SetEnvironment(env *Environment) {
+	ctx.configure("environment", func() {
+		ctx.environment = env
+	})
 }
 
-func (ctx *contextManagerImpl) Debug(on bool) {
-	ctx.debug = on
+func (ctx *contextManagerImpl) SetPluginBeanNamePrefix(prefix string) {
+	ctx.configure("plugin bean name prefix", func() {
+		ctx.pluginNamePrefix = prefix
+	})
 }
 
 func New() ApplicationContextManager {
-	return &contextManagerImpl{
+	logLevel := &slog.LevelVar{}
+	logLevel.Set(slog.LevelWarn)
+
+	return (&contextManagerImpl{
 		items:                    list.New(),
 		itemsMap:                 map[string]*gobean.PopulateItem{},
+		index:                    newTypeIndex(list.New()),
+		logger:                   newDefaultLogger(logLevel),
+		logLevel:                 logLevel,
 		injectionTag:             DefaultInjectionTag,
+		valueTag:                 DefaultValueTag,
+		environment:              NewEnvironment(OSEnvSource()),
 		pluginNamePrefix:         DefaultPluginNamePrefix,
-		debug:                    false,
 		setterNameFunc:           utils.SetterName,
 		exportedVariableNameFunc: utils.FileNameToExportedVariable,
-	}
+		scopes:                   map[string]Scope{ScopePrototype: prototypeScope{}},
+		conditions:               map[*gobean.PopulateItem][]condition{},
+		wiringCtx:                context.Background(),
+	}).addEventBus().addHealthRegistry()
 }
 
 func Initialize(callback func(ApplicationContextManager), onError func(error)) ApplicationContextManager {
@@ -481,7 +1043,7 @@ func Initialize(callback func(ApplicationContextManager), onError func(error)) A
 				if onError != nil {
 					onError(err)
 				} else {
-					log.Fatal(err)
+					panic(err)
 				}
 			}
 		}()
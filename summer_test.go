@@ -0,0 +1,230 @@
+package summer
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/linuzilla/summer/gobean"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type benchLogger interface {
+	Log(message string)
+}
+
+type benchConsole struct{}
+
+func (c *benchConsole) Log(message string) {}
+
+type benchRepository struct{}
+
+type benchHandler interface {
+	Handle() string
+}
+
+// a generated handler, like those registered by the thousand
+type benchRouteHandler struct {
+	route      string
+	Repository *benchRepository `inject:"*"`
+	Logger     benchLogger      `inject:"*"`
+}
+
+func (h *benchRouteHandler) Handle() string {
+	return h.route
+}
+
+type benchRouter struct {
+	Handlers []benchHandler `inject:"*"`
+	Logger   benchLogger    `inject:"*"`
+}
+
+// benchContext registers a router with its console, its repository and enough route handlers to reach beans.
+func benchContext(beans int) ApplicationContextManager {
+	ctx := New()
+	ctx.Add(&benchConsole{}, &benchRepository{}, &benchRouter{})
+
+	for n := 3; n < beans; n++ {
+		route := fmt.Sprintf("/route/%d", n)
+		ctx.AddWithName(route, &benchRouteHandler{route: route})
+	}
+	return ctx
+}
+
+func BenchmarkAutowiring(b *testing.B) {
+	for _, beans := range []int{10, 1000, 10000} {
+		b.Run(fmt.Sprintf("%d beans", beans), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				ctx := benchContext(beans)
+				b.StartTimer()
+
+				if err := ctx.PerformAutoWiringContext(context.Background()); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// linearItemsOf is the lookup replaced by the type index, walking through every bean.
+func linearItemsOf(ctx *contextManagerImpl, modelType reflect.Type) []*gobean.PopulateItem {
+	var matchedItems []*gobean.PopulateItem
+
+	for e := ctx.items.Front(); e != nil; e = e.Next() {
+		if item := e.Value.(*gobean.PopulateItem); ctx.assignable(item, modelType) {
+			matchedItems = append(matchedItems, item)
+		}
+	}
+	return matchedItems
+}
+
+// BenchmarkLookupByType looks up a concrete type and an interface once per bean, as autowiring does,
+// with the type index and with the linear scan it replaced.
+func BenchmarkLookupByType(b *testing.B) {
+	types := []reflect.Type{typeOf[benchRepository](), typeOf[benchLogger]()}
+
+	for _, lookup := range []struct {
+		name     string
+		lookupBy func(ctx *contextManagerImpl, modelType reflect.Type) []*gobean.PopulateItem
+	}{
+		{"index", (*contextManagerImpl).itemsOf},
+		{"linear scan", linearItemsOf},
+	} {
+		for _, beans := range []int{10, 1000, 10000} {
+			b.Run(fmt.Sprintf("%s/%d beans", lookup.name, beans), func(b *testing.B) {
+				ctx := benchContext(beans).(*contextManagerImpl)
+
+				if err := ctx.PerformAutoWiringContext(context.Background()); err != nil {
+					b.Fatal(err)
+				}
+				b.ResetTimer()
+
+				for i := 0; i < b.N; i++ {
+					for n := 0; n < beans; n++ {
+						if matched := lookup.lookupBy(ctx, types[n%len(types)]); len(matched) != 1 {
+							b.Fatalf("%d beans matched", len(matched))
+						}
+					}
+				}
+			})
+		}
+	}
+}
+
+type singlePassStep struct {
+	Repository *benchRepository `inject:"*"`
+}
+
+func TestAutowiringIsSinglePass(t *testing.T) {
+	var log bytes.Buffer
+
+	ctx := benchContext(100)
+	ctx.Add(&singlePassStep{})
+	ctx.SetLogger(slog.New(slog.NewTextHandler(&log, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	if err := ctx.PerformAutoWiringContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(log.String(), `msg="autowiring done" passes=1 beans=101`) {
+		t.Errorf("not wired in a single pass:\n%s", log.String()[max(0, log.Len()-500):])
+	}
+}
+
+type lookupFirst struct {
+	ctx ApplicationContextManager
+	err error
+}
+
+func (f *lookupFirst) PostSummerConstruct() {
+	_, f.err = Get[*lookupSecond](f.ctx)
+}
+
+type lookupSecond struct {
+	First *lookupFirst `inject:"*"`
+}
+
+type lookupAbsent struct{}
+
+func TestGetTellsAbsentFromNotReady(t *testing.T) {
+	ctx := New()
+	first := &lookupFirst{ctx: ctx}
+	ctx.Add(first, &lookupSecond{})
+
+	if err := ctx.PerformAutoWiringContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !errors.Is(first.err, ErrNotWired) {
+		t.Errorf("a bean not wired yet: got %v, want %v", first.err, ErrNotWired)
+	}
+
+	if _, err := Get[*lookupAbsent](ctx); !errors.Is(err, ErrNoSuchBean) {
+		t.Errorf("an absent bean: got %v, want %v", err, ErrNoSuchBean)
+	}
+}
+
+type contextAware struct {
+	hasDeadline bool
+	err         error
+}
+
+func (c *contextAware) PostSummerConstructCtx(goCtx context.Context) error {
+	_, c.hasDeadline = goCtx.Deadline()
+	c.err = goCtx.Err()
+	return nil
+}
+
+type contextLazy struct {
+	contextAware
+}
+
+type contextBlocking struct{}
+
+func (c *contextBlocking) PostSummerConstructCtx(goCtx context.Context) error {
+	<-goCtx.Done()
+	return goCtx.Err()
+}
+
+func TestPostConstructSeesAutowiringContext(t *testing.T) {
+	goCtx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	ctx := New()
+	eager, lazy := &contextAware{}, &contextLazy{}
+	ctx.Add(eager)
+	ctx.AddWithOptions(lazy, Lazy())
+
+	if err := ctx.PerformAutoWiringContext(goCtx); err != nil {
+		t.Fatal(err)
+	}
+
+	if !eager.hasDeadline {
+		t.Error("an eager bean does not see the deadline")
+	}
+
+	cancel()
+
+	if _, err := Get[*contextLazy](ctx); err != nil {
+		t.Fatal(err)
+	} else if lazy.hasDeadline || lazy.err != nil {
+		t.Errorf("a lazy bean created after autowiring: deadline %v, error %v", lazy.hasDeadline, lazy.err)
+	}
+}
+
+func TestPostConstructIsCancelled(t *testing.T) {
+	goCtx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	ctx := New()
+	ctx.Add(&contextBlocking{})
+
+	if err := ctx.PerformAutoWiringContext(goCtx); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want %v", err, context.Canceled)
+	}
+}